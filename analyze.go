@@ -0,0 +1,406 @@
+package binder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Defaults for AnalysisConfig.
+const (
+	DefaultRepetitionWindow = 50
+	DefaultRepetitionTop    = 5
+)
+
+// AnalysisConfig selects the book to analyze and how repetitions are found.
+type AnalysisConfig struct {
+	InputFile string
+	// Window is how many words apart two uses of a word or phrase may be
+	// to count as a repetition; DefaultRepetitionWindow if zero.
+	Window int
+	// Top is how many repetitions to keep per scene and chapter;
+	// DefaultRepetitionTop if zero.
+	Top int
+}
+
+// ProseMetrics are readability and style measures of a piece of prose. The
+// counts are kept alongside the derived measures so that they can be summed.
+type ProseMetrics struct {
+	Words            int `json:"words"`
+	Sentences        int `json:"sentences"`
+	Syllables        int `json:"syllables"`
+	DialogueWords    int `json:"dialogue_words"`
+	Adverbs          int `json:"adverbs"` // words ending in -ly
+	PassiveSentences int `json:"passive_sentences"`
+
+	Grade             float64 `json:"grade"`               // Flesch-Kincaid grade level
+	AvgSentenceLength float64 `json:"avg_sentence_length"` // words per sentence
+	// DialogueRatio is the share of words inside quotation marks; the rest
+	// is narration.
+	DialogueRatio float64      `json:"dialogue_ratio"`
+	AdverbDensity float64      `json:"adverb_density"` // adverbs per word
+	PassiveRatio  float64      `json:"passive_ratio"`  // passive sentences per sentence
+	Repetitions   []Repetition `json:"repetitions,omitempty"`
+}
+
+// Repetition is a word or phrase used again within the repetition window.
+// Count is the number of such repeats, so a word used three times in close
+// succession has a Count of 2.
+type Repetition struct {
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+// SceneAnalysis holds the metrics of one scene.
+type SceneAnalysis struct {
+	Scene  string `json:"scene"`
+	Path   string `json:"path"`
+	Subdir string `json:"subdir,omitempty"`
+	ProseMetrics
+}
+
+// ChapterAnalysis holds the metrics of a chapter as a whole, with
+// repetitions found across scene boundaries, and of each of its scenes.
+type ChapterAnalysis struct {
+	Index int    `json:"index"` // 1-based position in reading order
+	Label string `json:"heading"`
+	ProseMetrics
+	Scenes []SceneAnalysis `json:"scenes"`
+}
+
+// BookAnalysis is the prose analysis of every chapter and section of a
+// book, in reading order.
+type BookAnalysis struct {
+	Title    string            `json:"title"`
+	Window   int               `json:"window"`
+	Chapters []ChapterAnalysis `json:"chapters"`
+}
+
+// AnalyzeBook computes prose metrics for every scene and chapter of a book.
+func AnalyzeBook(config AnalysisConfig) (*BookAnalysis, error) {
+	frontMatter, book, err := LoadBook(config.InputFile)
+	if err != nil {
+		return nil, err
+	}
+	window, top := config.Window, config.Top
+	if window <= 0 {
+		window = DefaultRepetitionWindow
+	}
+	if top <= 0 {
+		top = DefaultRepetitionTop
+	}
+	analysis := &BookAnalysis{Title: frontMatter.Title, Window: window}
+	index := 0
+	for chapter := range book.GetChapters() {
+		index += 1
+		// Only the story itself is measured, as in CollectStats.
+		if chapter.Matter != BodyMatter {
+			continue
+		}
+		if err := chapter.Validate(); err != nil {
+			return nil, err
+		}
+		ca := ChapterAnalysis{Index: index, Label: chapter.Label()}
+		var bodies []string
+		scenes, err := chapter.LoadScenes()
+		if err != nil {
+			return nil, err
+		}
+		for _, scene := range scenes {
+			path := scene.Path
+			bodies = append(bodies, scene.Body)
+			ca.Scenes = append(ca.Scenes, SceneAnalysis{
+				Scene:        filepath.Base(path),
+				Path:         path,
+				Subdir:       sceneSubdir(book, path),
+				ProseMetrics: AnalyzeProse(scene.Body, window, top),
+			})
+		}
+		ca.ProseMetrics = AnalyzeProse(strings.Join(bodies, "\n\n"), window, top)
+		analysis.Chapters = append(analysis.Chapters, ca)
+	}
+	return analysis, nil
+}
+
+// AnalyzeProse measures markdown text, with markup stripped as for
+// CountMarkdown. Repetitions are looked for within window words and the
+// top most frequent are kept.
+func AnalyzeProse(text string, window, top int) ProseMetrics {
+	var m ProseMetrics
+	var words []string // normalized, for repetitions
+	for _, block := range proseBlocks(text) {
+		inDialogue := false
+		var sentence []string
+		endSentence := func() {
+			if len(sentence) == 0 {
+				return
+			}
+			m.Sentences++
+			if isPassive(sentence) {
+				m.PassiveSentences++
+			}
+			sentence = nil
+		}
+		for _, token := range proseWords(block) {
+			word := normalizeWord(token)
+			words = append(words, word)
+			sentence = append(sentence, word)
+			m.Words++
+			m.Syllables += syllables(word)
+			if isLyAdverb(word) {
+				m.Adverbs++
+			}
+
+			if (strings.HasPrefix(token, "“") || strings.HasPrefix(token, "\"")) && !inDialogue {
+				inDialogue = true
+			}
+			if inDialogue {
+				m.DialogueWords++
+			}
+			closing := strings.TrimRight(token, ".,;:!?…)")
+			if strings.HasSuffix(closing, "”") || strings.HasSuffix(closing, "\"") {
+				inDialogue = false
+			}
+			if endsSentence(token, word) {
+				endSentence()
+			}
+		}
+		// Headings and paragraphs end sentences that lack punctuation.
+		endSentence()
+	}
+	if m.Words > 0 {
+		sentences := float64(max(m.Sentences, 1))
+		m.AvgSentenceLength = round2(float64(m.Words) / sentences)
+		m.Grade = round2(0.39*float64(m.Words)/sentences + 11.8*float64(m.Syllables)/float64(m.Words) - 15.59)
+		m.DialogueRatio = round2(float64(m.DialogueWords) / float64(m.Words))
+		m.AdverbDensity = round2(float64(m.Adverbs) / float64(m.Words))
+	}
+	if m.Sentences > 0 {
+		m.PassiveRatio = round2(float64(m.PassiveSentences) / float64(m.Sentences))
+	}
+	m.Repetitions = findRepetitions(words, window, top)
+	return m
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// normalizeWord lowercases a word and trims the punctuation around it,
+// keeping inner apostrophes and hyphens.
+func normalizeWord(token string) string {
+	word := strings.TrimFunc(token, func(r rune) bool { return !isWordRune(r) })
+	return strings.ToLower(strings.ReplaceAll(word, "’", "'"))
+}
+
+// sentenceAbbreviations end in a period without ending a sentence.
+var sentenceAbbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "st": true, "jr": true,
+	"sr": true, "prof": true, "vs": true, "etc": true, "e.g": true, "i.e": true,
+}
+
+// endsSentence reports whether a token closes a sentence: it ends in
+// terminal punctuation, possibly followed by closing quotes or brackets.
+func endsSentence(token, word string) bool {
+	trimmed := strings.TrimRight(token, "\"'”’)]*_")
+	switch {
+	case strings.HasSuffix(trimmed, "!"), strings.HasSuffix(trimmed, "?"), strings.HasSuffix(trimmed, "…"):
+		return true
+	case strings.HasSuffix(trimmed, "."):
+		return !sentenceAbbreviations[word]
+	}
+	return false
+}
+
+// syllables estimates the syllables in an English word by counting vowel
+// groups, less a silent final e. Every word has at least one.
+func syllables(word string) int {
+	count := 0
+	previousVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouyàáâäèéêëìíîïòóôöùúûü", r)
+		if vowel && !previousVowel {
+			count++
+		}
+		previousVowel = vowel
+	}
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	return max(count, 1)
+}
+
+// notAdverbs end in -ly but are not adverbs.
+var notAdverbs = map[string]bool{
+	"only": true, "family": true, "early": true, "reply": true, "supply": true,
+	"apply": true, "holy": true, "ugly": true, "belly": true, "jelly": true,
+	"bully": true, "rally": true, "ally": true, "italy": true, "july": true,
+	"lily": true, "silly": true, "lovely": true, "lonely": true, "friendly": true,
+	"likely": true, "daily": true, "weekly": true, "monthly": true, "yearly": true,
+	"elderly": true, "costly": true, "deadly": true, "lively": true, "curly": true,
+	"surly": true, "burly": true, "chilly": true, "hilly": true, "assembly": true,
+}
+
+func isLyAdverb(word string) bool {
+	return len(word) > 4 && strings.HasSuffix(word, "ly") && !notAdverbs[word]
+}
+
+var beVerbs = map[string]bool{
+	"am": true, "is": true, "are": true, "was": true, "were": true,
+	"be": true, "been": true, "being": true, "get": true, "got": true,
+	"gets": true, "gotten": true,
+}
+
+// irregularParticiples are past participles that do not end in -ed.
+var irregularParticiples = map[string]bool{
+	"born": true, "bought": true, "brought": true, "built": true, "caught": true,
+	"chosen": true, "done": true, "drawn": true, "driven": true, "eaten": true,
+	"felt": true, "forgotten": true, "found": true, "frozen": true, "given": true,
+	"grown": true, "heard": true, "held": true, "hidden": true, "hit": true,
+	"hurt": true, "kept": true, "known": true, "led": true, "left": true,
+	"lost": true, "made": true, "meant": true, "paid": true, "put": true,
+	"said": true, "seen": true, "sent": true, "set": true, "shot": true,
+	"shown": true, "sold": true, "spoken": true, "stolen": true, "taken": true,
+	"taught": true, "thought": true, "thrown": true, "told": true, "torn": true,
+	"understood": true, "woken": true, "won": true, "worn": true, "written": true,
+}
+
+// isPassive is a heuristic for the passive voice: a form of "to be" or "to
+// get" followed, at most one word later, by a past participle, as in "was
+// taken" or "were quickly painted".
+func isPassive(sentence []string) bool {
+	for i, word := range sentence {
+		if !beVerbs[word] {
+			continue
+		}
+		for _, next := range sentence[i+1 : min(i+3, len(sentence))] {
+			if irregularParticiples[next] || len(next) > 3 && strings.HasSuffix(next, "ed") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stopWords are too common to be worth reporting as repetitions.
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`a about after again all also an and any are as at
+		back be because been before but by can could did do down for from had has
+		have he her here him his how i if in into is it its it's just like me more
+		my no not now of off on one or our out over said she so some than that the
+		their them then there they this to too up very was we were what when where
+		which while who will with would you your i'm don't didn't`) {
+		stopWords[word] = true
+	}
+}
+
+// findRepetitions counts the words and the two- and three-word phrases that
+// recur within window words of their previous use. Stop words, and phrases
+// made only of stop words, are ignored. The top most repeated are returned,
+// longer phrases first among equals.
+func findRepetitions(words []string, window, top int) []Repetition {
+	lastSeen := map[string]int{}
+	counts := map[string]int{}
+	for i := range words {
+		for n := 1; n <= 3 && i+n <= len(words); n++ {
+			phrase := words[i : i+n]
+			if allStopWords(phrase) || n == 1 && len([]rune(phrase[0])) < 3 {
+				continue
+			}
+			key := strings.Join(phrase, " ")
+			if last, ok := lastSeen[key]; ok && i-last <= window {
+				counts[key]++
+			}
+			lastSeen[key] = i
+		}
+	}
+	var repetitions []Repetition
+	for text, count := range counts {
+		repetitions = append(repetitions, Repetition{Text: text, Count: count})
+	}
+	sort.Slice(repetitions, func(i, j int) bool {
+		a, b := repetitions[i], repetitions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if wa, wb := strings.Count(a.Text, " "), strings.Count(b.Text, " "); wa != wb {
+			return wa > wb
+		}
+		return a.Text < b.Text
+	})
+	return repetitions[:min(top, len(repetitions))]
+}
+
+func allStopWords(words []string) bool {
+	for _, word := range words {
+		if !stopWords[word] {
+			return false
+		}
+	}
+	return true
+}
+
+// Write writes the analysis in the StatsTable or StatsJSON format.
+func (ba *BookAnalysis) Write(w io.Writer, format string) error {
+	switch format {
+	case "", StatsTable:
+		return ba.WriteReport(w)
+	case StatsJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(ba)
+	default:
+		return fmt.Errorf("unknown analysis format %q (want table or json)", format)
+	}
+}
+
+// WriteReport writes a table of metrics with a row per chapter followed by
+// its scenes, then the most repeated words and phrases of each scene.
+func (ba *BookAnalysis) WriteReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(label string, m ProseMetrics) {
+		fmt.Fprintf(tw, "%s\t%s\t%.1f\t%.1f\t%s\t%s\t%s\n", label, FormatThousands(m.Words), m.Grade,
+			m.AvgSentenceLength, formatPercent(m.DialogueRatio), formatPercent(m.AdverbDensity), formatPercent(m.PassiveRatio))
+	}
+	fmt.Fprintln(tw, "Chapter\tWords\tGrade\tWords/sentence\tDialogue\tAdverbs\tPassive")
+	for _, ca := range ba.Chapters {
+		row(ca.Label, ca.ProseMetrics)
+		for _, scene := range ca.Scenes {
+			row("  "+filepath.Join(scene.Subdir, scene.Scene), scene.ProseMetrics)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	header := false
+	for _, ca := range ba.Chapters {
+		for _, scene := range ca.Scenes {
+			if len(scene.Repetitions) == 0 {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(w, "\nRepeated within %d words:\n", ba.Window)
+				header = true
+			}
+			var items []string
+			for _, repetition := range scene.Repetitions {
+				items = append(items, fmt.Sprintf("%s (%d)", repetition.Text, repetition.Count))
+			}
+			if _, err := fmt.Fprintf(w, "  %s: %s\n", filepath.Join(scene.Subdir, scene.Scene), strings.Join(items, ", ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func formatPercent(ratio float64) string {
+	return fmt.Sprintf("%.0f%%", ratio*100)
+}
@@ -0,0 +1,100 @@
+package binder
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeProse(t *testing.T) {
+	scene, err := ReadScene("testdata/scenes/prose.md")
+	require.NoError(t, err)
+
+	m := AnalyzeProse(scene.Body, DefaultRepetitionWindow, DefaultRepetitionTop)
+	assert.Equal(t, 37, m.Words)
+	assert.Equal(t, 7, m.Sentences)
+	assert.Equal(t, 11, m.DialogueWords)
+	assert.Equal(t, 1, m.Adverbs)
+	assert.Equal(t, 2, m.PassiveSentences)
+	assert.Equal(t, 5.29, m.AvgSentenceLength)
+	assert.Equal(t, 0.3, m.DialogueRatio)
+	assert.Equal(t, 0.29, m.PassiveRatio)
+	assert.Equal(t, []Repetition{
+		{Text: "the door", Count: 3},
+		{Text: "door", Count: 3},
+		{Text: "left", Count: 1},
+		{Text: "quiet", Count: 1},
+	}, m.Repetitions)
+}
+
+func TestAnalyzeProse_Window(t *testing.T) {
+	m := AnalyzeProse("The lantern swung. Far off, a dog barked at the lantern.", 3, DefaultRepetitionTop)
+	assert.Empty(t, m.Repetitions)
+
+	m = AnalyzeProse("The lantern swung. Far off, a dog barked at the lantern.", 10, 1)
+	assert.Equal(t, []Repetition{{Text: "the lantern", Count: 1}}, m.Repetitions)
+}
+
+func TestAnalyzeProse_Grade(t *testing.T) {
+	simple := AnalyzeProse("The cat sat. The dog ran.", 50, 5)
+	complex := AnalyzeProse("Institutional considerations necessitated comprehensive reorganization of departmental responsibilities.", 50, 5)
+	assert.Less(t, simple.Grade, complex.Grade)
+	assert.Equal(t, 0.0, AnalyzeProse("", 50, 5).Grade)
+}
+
+func TestEndsSentence(t *testing.T) {
+	for token, want := range map[string]bool{
+		"done.":   true,
+		"done?\"": true,
+		"done!”":  true,
+		"wait…":   true,
+		"Mr.":     false,
+		"then,":   false,
+	} {
+		assert.Equal(t, want, endsSentence(token, normalizeWord(token)), token)
+	}
+}
+
+func TestSyllables(t *testing.T) {
+	for word, want := range map[string]int{"cat": 1, "table": 2, "make": 1, "organization": 5, "the": 1} {
+		assert.Equal(t, want, syllables(word), word)
+	}
+}
+
+func TestAnalyzeBook_SkipsMatterSections(t *testing.T) {
+	analysis, err := AnalyzeBook(AnalysisConfig{InputFile: "testdata/book_with_matter.yaml"})
+	require.NoError(t, err)
+	require.Len(t, analysis.Chapters, 2)
+	assert.Equal(t, 3, analysis.Chapters[0].Index)
+	assert.Equal(t, "Chapter One", analysis.Chapters[0].Label)
+}
+
+func TestAnalyzeBook(t *testing.T) {
+	analysis, err := AnalyzeBook(AnalysisConfig{InputFile: "testdata/valid_book.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRepetitionWindow, analysis.Window)
+	require.Len(t, analysis.Chapters, 4)
+
+	chapter := analysis.Chapters[1]
+	assert.Equal(t, 2, chapter.Index)
+	assert.Equal(t, "Chapter One", chapter.Label)
+	require.Len(t, chapter.Scenes, 2)
+	assert.Equal(t, "foo.md", chapter.Scenes[0].Scene)
+	assert.Equal(t, chapter.Scenes[0].Words+chapter.Scenes[1].Words, chapter.Words)
+
+	var buf bytes.Buffer
+	require.NoError(t, analysis.Write(&buf, StatsJSON))
+	var decoded BookAnalysis
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, analysis.Chapters[1].Words, decoded.Chapters[1].Words)
+
+	buf.Reset()
+	require.NoError(t, analysis.Write(&buf, StatsTable))
+	assert.Contains(t, buf.String(), "Chapter One")
+	assert.Contains(t, buf.String(), "  foo.md")
+
+	assert.Error(t, analysis.Write(&buf, "xml"))
+}
@@ -1,7 +1,6 @@
 package binder
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,122 +13,279 @@ type AssemblyConfig struct {
 	OutputDir     string
 	WordCount     bool
 	SceneHeadings bool // include scene filenames as ## headings
+	// Force allows assembling into a non-empty directory that binder did
+	// not create. Existing files are overwritten but never removed.
+	Force  bool
+	Drafts bool   // keep author notes in the output
+	Critic string // CriticAccept (default), CriticReject or CriticReview
 }
 
 // WordCountResult holds the word count for a single scene file.
 type WordCountResult struct {
-	Scene string
-	Count int
+	Scene        string `json:"scene"`         // file name, e.g. "opening.md"
+	Path         string `json:"path"`          // path to the scene file
+	Subdir       string `json:"subdir"`        // directory relative to base_dir, empty for base_dir itself
+	ChapterIndex int    `json:"chapter_index"` // 1-based position of the chapter in reading order
+	Chapter      string `json:"chapter"`       // the chapter's heading or label
+	Count        int    `json:"words"`
 }
 
 // AssembleMarkdown assembles a book's scenes into per-chapter markdown files
-// and writes a metadata.yaml for pandoc. Returns the parsed FrontMatter and
-// any word count results (if config.WordCount is true).
+// and writes a metadata.yaml for pandoc. The output is staged beside the
+// output directory and replaces it only once every file has been written.
+// Files whose scenes, heading and options are unchanged since the previous
+// assembly are carried over rather than rewritten, and files for chapters
+// that disappeared are removed, as recorded in the directory's manifest.
+// Returns the parsed FrontMatter and any word count results (if
+// config.WordCount is true).
 func AssembleMarkdown(config AssemblyConfig) (*FrontMatter, []WordCountResult, error) {
-	_ = os.RemoveAll(config.OutputDir)
-	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+	if err := ValidateCriticMode(config.Critic); err != nil {
 		return nil, nil, err
 	}
 	frontMatter, book, err := LoadBook(config.InputFile)
 	if err != nil {
 		return nil, nil, err
 	}
+	out, err := prepareOutputDir(config.OutputDir, config.InputFile, book.BaseDir, config.Force)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer out.abort()
+	options := book.sceneOptions()
+	options.Headings = config.SceneHeadings
+	options.Drafts = config.Drafts
+	options.Critic = config.Critic
 	var counts []WordCountResult
 	cnum := 1
+	index := 0
 	for chapter := range book.GetChapters() {
+		index += 1
 		if err := chapter.Validate(); err != nil {
 			return nil, nil, err
 		}
-		chapterOutPath := filepath.Join(config.OutputDir, fmt.Sprintf("%03d-%s.md", cnum, chapter.HeadingToFilename()))
-		cnum += 1
-		fd, err := os.OpenFile(chapterOutPath, os.O_CREATE|os.O_WRONLY|os.O_SYNC, 0644)
-		if err != nil {
-			return nil, nil, err
-		}
-		if chapter.Heading != "" {
-			if _, err := fmt.Fprintf(fd, "# %s\n\n", chapter.Heading); err != nil {
-				fd.Close()
+		options.SceneBreak = chapter.SceneBreak
+		options.Chapter = index
+		if chapter.StartsNamedPart() {
+			entry := partManifestEntry(fmt.Sprintf("%03d-%s.md", cnum, chapter.Part.HeadingToFilename()), chapter.Part)
+			cnum += 1
+			reused, err := out.reuse(entry)
+			if err != nil {
 				return nil, nil, err
 			}
-		}
-		if config.WordCount {
-			for _, scene := range chapter.Scenes {
-				wc, err := SceneWordCount(scene)
-				if err != nil {
-					fd.Close()
+			if !reused {
+				if err := WriteMarkdownPart(out.file(entry), chapter.Part); err != nil {
 					return nil, nil, err
 				}
-				counts = append(counts, WordCountResult{
-					Scene: filepath.Base(scene),
-					Count: wc,
-				})
 			}
 		}
-		if err := WriteMarkdownScenes(fd, chapter.Scenes, config.SceneHeadings); err != nil {
-			fd.Close()
+		entry, err := chapterManifestEntry(fmt.Sprintf("%03d-%s.md", cnum, chapter.HeadingToFilename()), chapter, options)
+		if err != nil {
 			return nil, nil, err
 		}
-		if err := fd.Close(); err != nil {
+		cnum += 1
+		if config.WordCount {
+			chapterCounts, err := chapterWordCounts(book, index, chapter)
+			if err != nil {
+				return nil, nil, err
+			}
+			counts = append(counts, chapterCounts...)
+		}
+		reused, err := out.reuse(entry)
+		if err != nil {
 			return nil, nil, err
 		}
+		if !reused {
+			if err := writeMarkdownChapter(out.file(entry), chapter, options); err != nil {
+				return nil, nil, err
+			}
+		}
 	}
-	if err := WriteMetadata(frontMatter, config.OutputDir); err != nil {
+	entry, err := specManifestEntry("metadata.yaml", config.InputFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	reused, err := out.reuse(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !reused {
+		if err := WriteMetadata(frontMatter, out.staging); err != nil {
+			return nil, nil, err
+		}
+		out.file(entry)
+	}
+	if err := out.commit(); err != nil {
 		return nil, nil, err
 	}
 	return frontMatter, counts, nil
 }
 
+// writeMarkdownChapter writes a chapter's heading and scenes to path.
+func writeMarkdownChapter(path string, chapter IteratedChapter, options SceneOptions) error {
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_SYNC, 0644)
+	if err != nil {
+		return err
+	}
+	if chapter.Heading != "" {
+		if _, err := fmt.Fprintf(fd, "# %s\n\n", chapter.Heading); err != nil {
+			fd.Close()
+			return err
+		}
+	}
+	scenes, err := chapter.LoadScenes()
+	if err != nil {
+		fd.Close()
+		return err
+	}
+	if err := writeMarkdownScenes(fd, scenes, options); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
+
+// SceneOptions controls how scene files are turned into chapter text.
+type SceneOptions struct {
+	// Headings precedes each scene with a ## heading naming its file,
+	// without extension. Only markdown output has scene headings.
+	Headings bool
+	// Typography is the smart punctuation pass, run when Typography.Smart
+	// is set.
+	Typography Typography
+	// Drafts keeps author notes, which are otherwise stripped.
+	Drafts bool
+	// Critic is the CriticMarkup mode; CriticAccept if empty. Review copies
+	// keep editor comments even without Drafts.
+	Critic string
+	// SceneBreak is written between scenes and in place of the thematic
+	// breaks within them.
+	SceneBreak SceneBreak
+	// Chapter is the position of the chapter in the book, which keeps the
+	// footnote labels of markdown chapter files apart.
+	Chapter int
+}
+
+// body returns the text of a scene as it appears in an assembled chapter.
+func (o SceneOptions) body(scene *Scene) string {
+	text := scene.Body
+	if !o.Drafts {
+		text = stripNotes(text, o.Critic == CriticReview)
+	}
+	return o.Typography.Apply(ApplyCritic(text, o.Critic))
+}
+
 // WriteMarkdownScenes writes the contents of scene files to fd, separated
 // by scene break markers. When sceneHeadings is true, each scene is preceded
 // by a ## heading with the scene filename (without extension).
 func WriteMarkdownScenes(fd *os.File, sceneFiles []string, sceneHeadings bool) error {
-	lastSceneIndex := len(sceneFiles) - 1
-	for i, sceneFile := range sceneFiles {
-		if sceneHeadings {
-			name := strings.TrimSuffix(filepath.Base(sceneFile), ".md")
+	return WriteMarkdownScenesOptions(fd, sceneFiles, SceneOptions{Headings: sceneHeadings})
+}
+
+// WriteMarkdownScenesOptions writes the contents of scene files to fd,
+// without their front matter, separated by scene break markers and
+// transformed as options ask. Footnotes are relabelled so that scenes
+// cannot clash, and their definitions are written after the last scene.
+func WriteMarkdownScenesOptions(fd *os.File, sceneFiles []string, options SceneOptions) error {
+	scenes, err := loadScenes(sceneFiles)
+	if err != nil {
+		return err
+	}
+	return writeMarkdownScenes(fd, scenes, options)
+}
+
+// writeMarkdownScenes is WriteMarkdownScenesOptions for scenes already read.
+func writeMarkdownScenes(fd *os.File, scenes []Scene, options SceneOptions) error {
+	lastSceneIndex := len(scenes) - 1
+	sceneBreak := options.SceneBreak.markdown()
+	notes := footnoter{chapter: options.Chapter}
+	var chapterNotes []footnote
+	for i := range scenes {
+		scene := &scenes[i]
+		if options.Headings {
+			name := strings.TrimSuffix(filepath.Base(scene.Path), ".md")
 			if _, err := fmt.Fprintf(fd, "## %s\n\n", name); err != nil {
 				return err
 			}
 		}
-		sceneText, err := os.ReadFile(sceneFile)
-		if err != nil {
-			return err
-		}
-		if _, err := fd.Write(sceneText); err != nil {
+		text, sceneNotes := notes.scene(options.body(scene))
+		chapterNotes = append(chapterNotes, sceneNotes...)
+		if _, err := fd.WriteString(replaceSceneBreaks(text, sceneBreak)); err != nil {
 			return err
 		}
 		if i < lastSceneIndex {
-			if _, err := fd.WriteString("\n\n***\n\n"); err != nil {
+			if _, err := fmt.Fprintf(fd, "\n\n%s\n\n", sceneBreak); err != nil {
 				return err
 			}
 		}
 	}
+	for _, note := range chapterNotes {
+		// Paragraphs after the first are indented to keep them in the note.
+		text := strings.ReplaceAll(note.text, "\n", "\n    ")
+		text = strings.ReplaceAll(text, "\n    \n", "\n\n")
+		if _, err := fmt.Fprintf(fd, "\n\n[^%s]: %s", note.id, text); err != nil {
+			return err
+		}
+	}
+	if len(chapterNotes) > 0 {
+		if _, err := fd.WriteString("\n"); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// SceneWordCount counts the words in a file using pure Go.
-func SceneWordCount(path string) (int, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return 0, err
+// WriteMarkdownPart writes a part-title file containing the part heading and
+// its optional text.
+func WriteMarkdownPart(path string, part *IteratedPart) error {
+	content := fmt.Sprintf("# %s\n", part.Heading)
+	if part.Text != "" {
+		content += fmt.Sprintf("\n%s\n", strings.TrimSpace(part.Text))
 	}
-	defer f.Close()
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// SceneWordCount counts the whitespace-separated words in a scene file. The
+// scene's front matter is not counted.
+func SceneWordCount(path string) (int, error) {
+	return SceneWordCountMode(path, CountPlain)
+}
 
-	count := 0
-	scanner := bufio.NewScanner(f)
-	scanner.Split(bufio.ScanWords)
-	for scanner.Scan() {
-		count++
+// chapterWordCounts counts the words of each scene in a chapter, the
+// index-th in the book's reading order.
+func chapterWordCounts(book *Book, index int, chapter IteratedChapter) ([]WordCountResult, error) {
+	counts := make([]WordCountResult, 0, len(chapter.Scenes))
+	for _, scene := range chapter.Scenes {
+		wc, err := SceneWordCountMode(scene, book.WordCount)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, WordCountResult{
+			Scene:        filepath.Base(scene),
+			Path:         scene,
+			Subdir:       sceneSubdir(book, scene),
+			ChapterIndex: index,
+			Chapter:      chapter.Label(),
+			Count:        wc,
+		})
 	}
-	if err := scanner.Err(); err != nil {
-		return 0, err
+	return counts, nil
+}
+
+// sceneSubdir returns the directory of a scene relative to the book's
+// base_dir, or "" for scenes directly inside it.
+func sceneSubdir(book *Book, scene string) string {
+	subdir, err := filepath.Rel(book.BaseDir, filepath.Dir(scene))
+	if err != nil || subdir == "." {
+		return ""
 	}
-	return count, nil
+	return subdir
 }
 
-// FormatWordCount formats a WordCountResult as a human-readable string.
+// FormatWordCount formats a WordCountResult as a human-readable string. The
+// scene is shown with its subdirectory so that scenes sharing a file name
+// can be told apart.
 func FormatWordCount(result WordCountResult) string {
-	return fmt.Sprintf("%s: %d words", result.Scene, result.Count)
+	return fmt.Sprintf("%s: %d words", filepath.Join(result.Subdir, result.Scene), result.Count)
 }
 
 // OutputFiles returns a sorted list of the assembled chapter markdown files
@@ -31,14 +31,18 @@ func TestAssembleMarkdown_ValidBook(t *testing.T) {
 
 	var mdFiles []string
 	hasMetadata := false
+	hasManifest := false
 	for _, e := range entries {
 		if e.Name() == "metadata.yaml" {
 			hasMetadata = true
+		} else if e.Name() == ManifestFile {
+			hasManifest = true
 		} else {
 			mdFiles = append(mdFiles, e.Name())
 		}
 	}
 	assert.True(t, hasMetadata, "metadata.yaml should be generated")
+	assert.True(t, hasManifest, "the output directory should be marked as binder output")
 	assert.Len(t, mdFiles, 4)
 
 	// Verify chapter file names
@@ -264,3 +268,89 @@ func TestOutputFiles(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, files, 4)
 }
+
+func TestAssembleMarkdown_WithParts(t *testing.T) {
+	outdir := t.TempDir()
+
+	config := AssemblyConfig{
+		InputFile: "testdata/book_with_parts.yaml",
+		OutputDir: outdir,
+	}
+	_, _, err := AssembleMarkdown(config)
+	require.NoError(t, err)
+
+	files, err := OutputFiles(outdir)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f))
+	}
+	assert.Equal(t, []string{
+		"001-prologue.md",
+		"002-part-one-the-fall.md",
+		"003-chapter-one.md",
+		"004-interlude.md",
+		"005-part-two-the-rise.md",
+		"006-chapter-two.md",
+		"007-epilogue.md",
+	}, names)
+
+	part, err := os.ReadFile(filepath.Join(outdir, "002-part-one-the-fall.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Part One: The Fall\n\n*Everything falls.*\n", string(part))
+}
+
+func TestAssembleMarkdown_WithMatterSections(t *testing.T) {
+	outdir := t.TempDir()
+
+	config := AssemblyConfig{
+		InputFile: "testdata/book_with_matter.yaml",
+		OutputDir: outdir,
+	}
+	_, _, err := AssembleMarkdown(config)
+	require.NoError(t, err)
+
+	files, err := OutputFiles(outdir)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f))
+	}
+	assert.Equal(t, []string{
+		"001-dedication.md",
+		"002-copyright.md",
+		"003-chapter-one.md",
+		"004-chapter-two.md",
+		"005-acknowledgments.md",
+		"006-about-test-author.md",
+	}, names)
+
+	dedication, err := os.ReadFile(filepath.Join(outdir, "001-dedication.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "For the readers.\n", string(dedication))
+
+	thanks, err := os.ReadFile(filepath.Join(outdir, "005-acknowledgments.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(thanks), "# Acknowledgments\n\n")
+}
+
+func TestAssembleMarkdown_WordCountDetails(t *testing.T) {
+	_, counts, err := AssembleMarkdown(AssemblyConfig{
+		InputFile: "testdata/subdirs/book.yaml",
+		OutputDir: t.TempDir(),
+		WordCount: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, counts, 2)
+
+	assert.Equal(t, WordCountResult{
+		Scene:        "opening.md",
+		Path:         filepath.Join("testdata", "subdirs", "manuscript", "two", "opening.md"),
+		Subdir:       "two",
+		ChapterIndex: 2,
+		Chapter:      "Chapter Two",
+		Count:        4,
+	}, counts[1])
+	assert.Equal(t, "one/opening.md: 3 words", FormatWordCount(counts[0]))
+	assert.Equal(t, "two/opening.md: 4 words", FormatWordCount(counts[1]))
+}
@@ -1,17 +1,15 @@
 package binder
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"iter"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
-
-	"github.com/divan/num2words"
 	"gopkg.in/yaml.v3"
 )
 
@@ -25,27 +23,152 @@ type FrontMatter struct {
 	ContactCityStateZip string `yaml:"contact_city_state_zip"`
 	ContactPhone        string `yaml:"contact_phone"`
 	ContactEmail        string `yaml:"contact_email"`
+	Language            string `yaml:"language,omitempty"`
+	Identifier          string `yaml:"identifier,omitempty"`
 }
 
 type Chapter struct {
-	Name      string   `yaml:"name,omitempty"`
-	Interlude bool     `yaml:"interlude,omitempty"`
-	Subdir    string   `yaml:"subdir,omitempty"`
-	Scenes    []string `yaml:"scenes"`
+	Name        string     `yaml:"name,omitempty"`
+	Interlude   bool       `yaml:"interlude,omitempty"`
+	Subdir      string     `yaml:"subdir,omitempty"`
+	TargetWords int        `yaml:"target_words,omitempty"`
+	SceneBreak  SceneBreak `yaml:"scene_break,omitempty"` // overrides the book's scene break
+	Scenes      []string   `yaml:"scenes"`
+}
+
+// Part groups chapters under a named part heading such as
+// "Part One: The Fall". A part without a name groups chapters without
+// emitting a heading.
+type Part struct {
+	Name        string    `yaml:"name,omitempty"`
+	Text        string    `yaml:"text,omitempty"` // optional text for the part-title page
+	Subdir      string    `yaml:"subdir,omitempty"`
+	TargetWords int       `yaml:"target_words,omitempty"`
+	Chapters    []Chapter `yaml:"chapters"`
+}
+
+// Section is a front or back matter section such as a dedication,
+// copyright page or about-the-author page.
+type Section struct {
+	Type  string `yaml:"type"`            // e.g. dedication, epigraph, copyright, acknowledgments, about_the_author, also_by
+	Title string `yaml:"title,omitempty"` // overrides the type's default heading
+	File  string `yaml:"file"`            // markdown file relative to base_dir; the .md extension is optional
+}
+
+// Section types with a conventional default heading. Types not listed here
+// have no heading unless the section sets a title.
+var sectionTitles = map[string]string{
+	"acknowledgments":  "Acknowledgments",
+	"about_the_author": "About the Author",
+	"also_by":          "Also By",
+	"foreword":         "Foreword",
+	"preface":          "Preface",
+	"afterword":        "Afterword",
 }
 
 type Book struct {
-	BaseDir  string `yaml:"base_dir"`
-	Chapters []Chapter
+	BaseDir             string `yaml:"base_dir"`
+	Chapters            []Chapter
+	Parts               []Part        `yaml:"parts,omitempty"`
+	FrontMatterSections []Section     `yaml:"front_matter_sections,omitempty"`
+	BackMatterSections  []Section     `yaml:"back_matter_sections,omitempty"`
+	Language            string        `yaml:"language,omitempty"` // defaults to the front matter language
+	Numbering           Numbering     `yaml:"numbering,omitempty"`
+	Latex               LatexSettings `yaml:"latex,omitempty"`
+	TargetWords         int           `yaml:"target_words,omitempty"`
+	SceneWords          WordRange     `yaml:"scene_words,omitempty"` // expected length of each scene
+	WordCount           string        `yaml:"word_count,omitempty"`  // CountPlain, CountMarkdown or CountEstimate
+	Typography          Typography    `yaml:"typography,omitempty"`
+	SceneBreak          SceneBreak    `yaml:"scene_break,omitempty"`
+	// Footnotes is where notes are set: FootnotesInline, FootnotesChapter
+	// or FootnotesBook. Markdown output keeps them as pandoc footnotes,
+	// leaving their placement to pandoc.
+	Footnotes string `yaml:"footnotes,omitempty"`
+}
+
+// WordRange bounds a word count. A zero bound is not checked.
+type WordRange struct {
+	Min int `yaml:"min,omitempty"`
+	Max int `yaml:"max,omitempty"`
+}
+
+// Contains reports whether words lies within the range.
+func (r WordRange) Contains(words int) bool {
+	return (r.Min == 0 || words >= r.Min) && (r.Max == 0 || words <= r.Max)
+}
+
+func (r WordRange) String() string {
+	switch {
+	case r.Min > 0 && r.Max > 0:
+		return fmt.Sprintf("%s-%s", FormatThousands(r.Min), FormatThousands(r.Max))
+	case r.Min > 0:
+		return "at least " + FormatThousands(r.Min)
+	default:
+		return "at most " + FormatThousands(r.Max)
+	}
+}
+
+// Matter identifies which division of the book a chapter belongs to.
+type Matter int
+
+const (
+	BodyMatter Matter = iota
+	FrontMatterSection
+	BackMatterSection
+)
+
+// IteratedPart describes the part a chapter belongs to.
+type IteratedPart struct {
+	Index       int // 1-based position among the book's parts
+	Heading     string
+	Text        string
+	TargetWords int
+}
+
+func (ip IteratedPart) HeadingToFilename() string {
+	if ip.Heading == "" {
+		return "part"
+	}
+	return slugify(ip.Heading)
 }
 
 type IteratedChapter struct {
-	Filename string
-	Heading  string
-	Scenes   []string
+	Filename  string
+	Heading   string
+	Number    int // chapter number, or 0 for unnumbered chapters
+	Interlude bool
+	Scenes    []string
+	// SceneData holds the parsed scene files, in the order of Scenes. It is
+	// only filled in by GetChapters; a scene that could not be read is left
+	// with just its Path, and LoadScenes reports the error.
+	SceneData []Scene
+	// Part is the part containing the chapter, or nil for chapters listed
+	// directly under the book. PartStart is set on the first chapter of
+	// each part so writers can emit the part heading before it.
+	Part      *IteratedPart
+	PartStart bool
+	// Matter is BodyMatter for chapters; front and back matter sections
+	// also carry their section type.
+	Matter      Matter
+	SectionType string
+	TargetWords int
+	// SceneBreak is the chapter's own scene break or else the book's, with
+	// its image path resolved.
+	SceneBreak SceneBreak
+	// headingErr is set when the numbering template failed to render the
+	// chapter's heading, and sceneErr when a scene file failed to load.
+	headingErr, sceneErr error
+	// chapterLabel and interludeLabel are the book's words for "Chapter"
+	// and "Interlude", for labelling chapters without a heading.
+	chapterLabel, interludeLabel string
 }
 
+// Validate checks that the chapter's heading rendered and its scene files
+// exist.
 func (ic IteratedChapter) Validate() error {
+	if ic.headingErr != nil {
+		return ic.headingErr
+	}
 	for _, scene := range ic.Scenes {
 		if _, err := os.Stat(scene); err != nil {
 			return err
@@ -56,43 +179,197 @@ func (ic IteratedChapter) Validate() error {
 
 func (ic IteratedChapter) HeadingToFilename() string {
 	if ic.Heading == "" {
+		if ic.Matter != BodyMatter && ic.SectionType != "" {
+			return slugify(ic.SectionType)
+		}
+		if ic.Number > 0 {
+			return fmt.Sprintf("chapter-%d", ic.Number)
+		}
 		return "interlude"
 	}
-	return strings.ToLower(strings.ReplaceAll(ic.Heading, " ", "-"))
+	return slugify(ic.Heading)
 }
 
+// Label returns a human-readable name for the chapter: its heading or, for
+// untitled sections and interludes, a description of what they are in the
+// book's language.
+func (ic IteratedChapter) Label() string {
+	switch {
+	case ic.Heading != "":
+		return ic.Heading
+	case ic.Matter != BodyMatter && ic.SectionType != "":
+		label := strings.ReplaceAll(ic.SectionType, "_", " ")
+		return strings.ToUpper(label[:1]) + label[1:]
+	case ic.Number > 0:
+		return fmt.Sprintf("%s %d", cmp.Or(ic.chapterLabel, "Chapter"), ic.Number)
+	default:
+		return cmp.Or(ic.interludeLabel, "Interlude")
+	}
+}
+
+// slugify lowercases a heading and joins its words with dashes, dropping
+// punctuation that is awkward or invalid in filenames and URL fragments.
+func slugify(heading string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// StartsNamedPart reports whether a part heading should be emitted before
+// this chapter.
+func (ic IteratedChapter) StartsNamedPart() bool {
+	return ic.PartStart && ic.Part != nil && ic.Part.Heading != ""
+}
+
+// sceneOptions returns the book's settings for turning scene files into
+// chapter text.
+func (b *Book) sceneOptions() SceneOptions {
+	return SceneOptions{Typography: b.Typography.resolve(b.Language)}
+}
+
+// GetChapters yields the book's chapters in order: front matter sections,
+// the chapters listed directly under the book, those of each part and
+// finally back matter sections, each with its scene files parsed. Chapter
+// numbering skips front and back matter and continues across parts unless
+// the book's numbering restarts per part.
 func (b *Book) GetChapters() iter.Seq[IteratedChapter] {
-	caser := cases.Title(language.English)
+	return b.chapters(true)
+}
+
+// chapters is GetChapters, only reading the scene files when load is set,
+// for callers that need no more than their paths.
+func (b *Book) chapters(load bool) iter.Seq[IteratedChapter] {
+	locale, _ := LookupLocale(b.Language)
+	// An invalid template is rejected by LoadBook; books built in code fall
+	// back to the default heading.
+	tmpl, _ := b.Numbering.template()
 	return func(yield func(IteratedChapter) bool) {
 		cn := 1
-		for _, chapter := range b.Chapters {
+		emit := func(chapter Chapter, baseDir string, part *IteratedPart, partStart bool) bool {
 			ic := &IteratedChapter{
-				Scenes: make([]string, len(chapter.Scenes)),
+				Interlude:      chapter.Interlude,
+				Scenes:         make([]string, len(chapter.Scenes)),
+				Part:           part,
+				PartStart:      partStart,
+				TargetWords:    chapter.TargetWords,
+				SceneBreak:     b.SceneBreak.resolve(b.BaseDir),
+				chapterLabel:   b.Numbering.label(locale),
+				interludeLabel: locale.InterludeLabel,
+			}
+			if !chapter.SceneBreak.IsZero() {
+				ic.SceneBreak = chapter.SceneBreak.resolve(b.BaseDir)
 			}
 			var chapterBaseDir string
 			if chapter.Subdir != "" {
-				chapterBaseDir = filepath.Join(b.BaseDir, chapter.Subdir)
+				chapterBaseDir = filepath.Join(baseDir, chapter.Subdir)
 			} else {
-				chapterBaseDir = b.BaseDir
+				chapterBaseDir = baseDir
 			}
+			var name string
 			if chapter.Name != "" {
-				ic.Heading = caser.String(chapter.Name)
-			} else {
-				if !chapter.Interlude {
-					ic.Heading = fmt.Sprintf("Chapter %s", caser.String(num2words.Convert(cn)))
-					cn += 1
-				}
+				name = locale.Title(chapter.Name)
+			}
+			if !chapter.Interlude && (name == "" || b.Numbering.numbersNamed()) {
+				ic.Number = cn
+				cn += 1
 			}
+			ic.Heading, ic.headingErr = b.Numbering.heading(tmpl, name, ic.Number, locale)
 			for i, s := range chapter.Scenes {
 				ic.Scenes[i] = fmt.Sprintf("%s.md", filepath.Join(chapterBaseDir, s))
 			}
-			if !yield(*ic) {
+			if load {
+				ic.SceneData, ic.sceneErr = loadScenes(ic.Scenes)
+			}
+			return yield(*ic)
+		}
+		section := func(s Section, matter Matter) bool {
+			heading := s.Title
+			if heading == "" {
+				heading = sectionTitles[s.Type]
+			}
+			file := filepath.Join(b.BaseDir, s.File)
+			if filepath.Ext(file) == "" {
+				file += ".md"
+			}
+			ic := IteratedChapter{
+				Heading:     heading,
+				Scenes:      []string{file},
+				Matter:      matter,
+				SectionType: s.Type,
+				SceneBreak:  b.SceneBreak.resolve(b.BaseDir),
+			}
+			if load {
+				ic.SceneData, ic.sceneErr = loadScenes(ic.Scenes)
+			}
+			return yield(ic)
+		}
+		for _, s := range b.FrontMatterSections {
+			if !section(s, FrontMatterSection) {
+				return
+			}
+		}
+		for _, chapter := range b.Chapters {
+			if !emit(chapter, b.BaseDir, nil, false) {
+				return
+			}
+		}
+		for i, part := range b.Parts {
+			ip := &IteratedPart{
+				Index:       i + 1,
+				Text:        part.Text,
+				TargetWords: part.TargetWords,
+			}
+			if part.Name != "" {
+				ip.Heading = locale.Title(part.Name)
+			}
+			if b.Numbering.RestartPerPart {
+				cn = 1
+			}
+			partBaseDir := b.BaseDir
+			if part.Subdir != "" {
+				partBaseDir = filepath.Join(b.BaseDir, part.Subdir)
+			}
+			for j, chapter := range part.Chapters {
+				if !emit(chapter, partBaseDir, ip, j == 0) {
+					return
+				}
+			}
+		}
+		for _, s := range b.BackMatterSections {
+			if !section(s, BackMatterSection) {
 				return
 			}
 		}
 	}
 }
 
+// SpecError is an invalid value in the book spec, such as an unknown
+// numbering style. Key is the key under "book" the value was given for.
+type SpecError struct {
+	Key string
+	Err error
+}
+
+func (e *SpecError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SpecError) Unwrap() error {
+	return e.Err
+}
+
 type BookSpec struct {
 	Book Book `yaml:"book"`
 }
@@ -113,6 +390,29 @@ func LoadBook(fileName string) (*FrontMatter, *Book, error) {
 	}
 	inputDir := filepath.Dir(fileName)
 	book := &(bs.Book)
+	if err := book.Numbering.Validate(); err != nil {
+		return nil, nil, &SpecError{Key: "numbering", Err: err}
+	}
+	if err := ValidateCountMode(book.WordCount); err != nil {
+		return nil, nil, &SpecError{Key: "word_count", Err: err}
+	}
+	if err := book.Typography.Validate(); err != nil {
+		return nil, nil, &SpecError{Key: "typography", Err: err}
+	}
+	if err := ValidateFootnoteMode(book.Footnotes); err != nil {
+		return nil, nil, &SpecError{Key: "footnotes", Err: err}
+	}
+	// The language may be given on either document; each fills in the other.
+	if book.Language == "" {
+		book.Language = fm.Language
+	} else if fm.Language == "" {
+		fm.Language = book.Language
+	}
+	for chapter := range book.chapters(false) {
+		if chapter.headingErr != nil {
+			return nil, nil, &SpecError{Key: "numbering", Err: chapter.headingErr}
+		}
+	}
 	relativeDir := filepath.Join(inputDir, book.BaseDir)
 	info, err := os.Stat(relativeDir)
 	if err != nil {
@@ -875,3 +875,217 @@ func TestBook_GetChapters_InterleavedInterludes_Integration(t *testing.T) {
 	// Chapter 2 - should be "Chapter Two"
 	assert.Equal(t, "Chapter Two", chapters[3].Heading)
 }
+
+func TestFrontMatter_LanguageAndIdentifier(t *testing.T) {
+	yamlData := `
+title: Test Book
+language: fr
+identifier: "isbn:9780000000000"
+`
+	var fm FrontMatter
+	err := yaml.Unmarshal([]byte(yamlData), &fm)
+	require.NoError(t, err)
+
+	assert.Equal(t, "fr", fm.Language)
+	assert.Equal(t, "isbn:9780000000000", fm.Identifier)
+}
+
+func TestBook_GetChapters_ExposesInterludeFlag(t *testing.T) {
+	book := &Book{
+		BaseDir: "manuscript",
+		Chapters: []Chapter{
+			{Interlude: true, Name: "Intermission", Scenes: []string{"pause"}},
+			{Scenes: []string{"scene1"}},
+		},
+	}
+
+	var chapters []IteratedChapter
+	for ch := range book.GetChapters() {
+		chapters = append(chapters, ch)
+	}
+
+	require.Len(t, chapters, 2)
+	assert.True(t, chapters[0].Interlude)
+	assert.False(t, chapters[1].Interlude)
+}
+
+func TestLoadBook_LatexSettings(t *testing.T) {
+	_, book, err := LoadBook("testdata/book_with_latex.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "memoir", book.Latex.DocumentClass)
+	assert.Equal(t, "5.5x8.5", book.Latex.TrimSize)
+	assert.Equal(t, "12pt", book.Latex.FontSize)
+	assert.Equal(t, "EB Garamond", book.Latex.Font)
+}
+
+// Parts tests
+
+func TestBook_GetChapters_Parts(t *testing.T) {
+	book := &Book{
+		BaseDir: "base",
+		Chapters: []Chapter{
+			{Name: "Prologue", Scenes: []string{"intro"}},
+		},
+		Parts: []Part{
+			{Name: "part one: the fall", Text: "Falling.", Subdir: "one", Chapters: []Chapter{
+				{Scenes: []string{"a"}},
+				{Subdir: "extra", Scenes: []string{"b"}},
+			}},
+			{Name: "part two", Chapters: []Chapter{
+				{Scenes: []string{"c"}},
+			}},
+		},
+	}
+
+	var chapters []IteratedChapter
+	for ch := range book.GetChapters() {
+		chapters = append(chapters, ch)
+	}
+
+	require.Len(t, chapters, 4)
+
+	assert.Nil(t, chapters[0].Part)
+	assert.False(t, chapters[0].StartsNamedPart())
+
+	require.NotNil(t, chapters[1].Part)
+	assert.Equal(t, 1, chapters[1].Part.Index)
+	assert.Equal(t, "Part One: The Fall", chapters[1].Part.Heading)
+	assert.Equal(t, "Falling.", chapters[1].Part.Text)
+	assert.True(t, chapters[1].StartsNamedPart())
+	assert.Equal(t, "Chapter One", chapters[1].Heading)
+	assert.Equal(t, []string{"base/one/a.md"}, chapters[1].Scenes)
+
+	assert.Same(t, chapters[1].Part, chapters[2].Part)
+	assert.False(t, chapters[2].PartStart)
+	assert.Equal(t, "Chapter Two", chapters[2].Heading)
+	assert.Equal(t, []string{"base/one/extra/b.md"}, chapters[2].Scenes)
+
+	// Numbering continues across parts
+	assert.Equal(t, 2, chapters[3].Part.Index)
+	assert.True(t, chapters[3].PartStart)
+	assert.Equal(t, "Chapter Three", chapters[3].Heading)
+	assert.Equal(t, []string{"base/c.md"}, chapters[3].Scenes)
+}
+
+func TestBook_GetChapters_UnnamedPartHasNoHeading(t *testing.T) {
+	book := &Book{
+		BaseDir: "base",
+		Parts: []Part{
+			{Chapters: []Chapter{{Scenes: []string{"a"}}}},
+		},
+	}
+
+	var chapters []IteratedChapter
+	for ch := range book.GetChapters() {
+		chapters = append(chapters, ch)
+	}
+
+	require.Len(t, chapters, 1)
+	assert.True(t, chapters[0].PartStart)
+	assert.False(t, chapters[0].StartsNamedPart())
+}
+
+func TestLoadBook_WithParts(t *testing.T) {
+	_, book, err := LoadBook("testdata/book_with_parts.yaml")
+	require.NoError(t, err)
+
+	require.Len(t, book.Chapters, 1)
+	require.Len(t, book.Parts, 3)
+	assert.Equal(t, "Part One: The Fall", book.Parts[0].Name)
+	assert.Equal(t, "*Everything falls.*", book.Parts[0].Text)
+	require.Len(t, book.Parts[0].Chapters, 2)
+	assert.Empty(t, book.Parts[2].Name)
+}
+
+func TestIteratedPart_HeadingToFilename(t *testing.T) {
+	assert.Equal(t, "part-two", IteratedPart{Heading: "Part Two"}.HeadingToFilename())
+	assert.Equal(t, "part", IteratedPart{}.HeadingToFilename())
+}
+
+func TestIteratedChapter_HeadingToFilename_Punctuation(t *testing.T) {
+	ic := IteratedChapter{Heading: "Part One: The Fall -- Again?"}
+
+	assert.Equal(t, "part-one-the-fall-again", ic.HeadingToFilename())
+}
+
+// Front and back matter tests
+
+func TestBook_GetChapters_FrontAndBackMatter(t *testing.T) {
+	book := &Book{
+		BaseDir: "base",
+		FrontMatterSections: []Section{
+			{Type: "dedication", File: "dedication"},
+			{Type: "epigraph", File: "front/epigraph.md"},
+		},
+		Chapters: []Chapter{
+			{Scenes: []string{"a"}},
+		},
+		Parts: []Part{
+			{Name: "Part One", Chapters: []Chapter{{Scenes: []string{"b"}}}},
+		},
+		BackMatterSections: []Section{
+			{Type: "acknowledgments", File: "thanks"},
+			{Type: "also_by", Title: "Also by Test Author", File: "also"},
+		},
+	}
+
+	var chapters []IteratedChapter
+	for ch := range book.GetChapters() {
+		chapters = append(chapters, ch)
+	}
+
+	require.Len(t, chapters, 6)
+
+	assert.Equal(t, FrontMatterSection, chapters[0].Matter)
+	assert.Equal(t, "dedication", chapters[0].SectionType)
+	assert.Empty(t, chapters[0].Heading)
+	assert.Zero(t, chapters[0].Number)
+	assert.Equal(t, []string{"base/dedication.md"}, chapters[0].Scenes)
+	assert.Equal(t, "dedication", chapters[0].HeadingToFilename())
+	assert.Equal(t, []string{"base/front/epigraph.md"}, chapters[1].Scenes)
+
+	// Sections do not take part in chapter numbering
+	assert.Equal(t, BodyMatter, chapters[2].Matter)
+	assert.Equal(t, "Chapter One", chapters[2].Heading)
+	assert.Equal(t, "Chapter Two", chapters[3].Heading)
+
+	assert.Equal(t, BackMatterSection, chapters[4].Matter)
+	assert.Equal(t, "Acknowledgments", chapters[4].Heading)
+	assert.Equal(t, "Also by Test Author", chapters[5].Heading)
+}
+
+func TestIteratedChapter_Label(t *testing.T) {
+	assert.Equal(t, "Chapter One", IteratedChapter{Heading: "Chapter One"}.Label())
+	assert.Equal(t, "About the author", IteratedChapter{Matter: BackMatterSection, SectionType: "about_the_author"}.Label())
+	assert.Equal(t, "Chapter 3", IteratedChapter{Number: 3}.Label())
+	assert.Equal(t, "Interlude", IteratedChapter{Interlude: true}.Label())
+}
+
+func TestIteratedChapter_LocalizedLabel(t *testing.T) {
+	book := &Book{
+		BaseDir:   "m",
+		Language:  "de",
+		Numbering: Numbering{Style: NumberNone},
+		Chapters:  []Chapter{{Scenes: []string{"a"}}, {Interlude: true, Scenes: []string{"b"}}},
+	}
+	var labels []string
+	for chapter := range book.GetChapters() {
+		labels = append(labels, chapter.Label())
+	}
+	assert.Equal(t, []string{"Kapitel 1", "Zwischenspiel"}, labels)
+}
+
+func TestLoadBook_WithMatterSections(t *testing.T) {
+	_, book, err := LoadBook("testdata/book_with_matter.yaml")
+	require.NoError(t, err)
+
+	require.Len(t, book.FrontMatterSections, 2)
+	assert.Equal(t, Section{Type: "dedication", File: "matter/dedication"}, book.FrontMatterSections[0])
+	require.Len(t, book.BackMatterSections, 2)
+	assert.Equal(t, "About Test Author", book.BackMatterSections[1].Title)
+
+	for ch := range book.GetChapters() {
+		assert.NoError(t, ch.Validate(), "chapter %q should have all valid scenes", ch.Label())
+	}
+}
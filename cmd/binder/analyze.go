@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+var analyzeCommand = &cli.Command{
+	Name:   "analyze",
+	Usage:  "report readability and prose metrics per chapter and scene",
+	Action: analyze,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage:   "output format: table or json",
+			Value:   binder.StatsTable,
+		},
+		&cli.IntFlag{
+			Name:  "window",
+			Usage: "how many words apart a word or phrase may recur to count as repeated",
+			Value: binder.DefaultRepetitionWindow,
+		},
+		&cli.IntFlag{
+			Name:  "top",
+			Usage: "how many repeated words and phrases to list per scene",
+			Value: binder.DefaultRepetitionTop,
+		},
+	},
+}
+
+func analyze(ctx context.Context, cmd *cli.Command) error {
+	analysis, err := binder.AnalyzeBook(binder.AnalysisConfig{
+		InputFile: cmd.String("input"),
+		Window:    int(cmd.Int("window")),
+		Top:       int(cmd.Int("top")),
+	})
+	if err != nil {
+		return err
+	}
+	return analysis.Write(os.Stdout, cmd.String("format"))
+}
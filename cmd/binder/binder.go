@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
@@ -39,8 +40,25 @@ func main() {
 						Aliases: []string{"w"},
 						Usage:   "print word count for each scene",
 					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "assemble into a non-empty directory that binder did not create",
+					},
+					draftsFlag(),
+					criticFlag(),
+					watchFlag(),
 				},
 			},
+			analyzeCommand,
+			docxCommand,
+			epubCommand,
+			htmlCommand,
+			latexCommand,
+			lintCommand,
+			notesCommand,
+			progressCommand,
+			serveCommand,
+			statsCommand,
 		},
 		Usage: "assemble a book",
 	}
@@ -49,18 +67,53 @@ func main() {
 	}
 }
 
+// draftsFlag returns the --drafts flag shared by the commands that render
+// scenes.
+func draftsFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "drafts",
+		Usage: "keep author notes, comments and TK placeholders in the output",
+	}
+}
+
+// criticFlag returns the --critic flag shared by the commands that render
+// scenes.
+func criticFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "critic",
+		Usage: "CriticMarkup changes: accept, reject or review (shown as tracked changes)",
+		Value: binder.CriticAccept,
+	}
+}
+
 func markdown(ctx context.Context, cmd *cli.Command) error {
 	config := binder.AssemblyConfig{
 		InputFile: cmd.String("input"),
 		OutputDir: cmd.String("outdir"),
-		WordCount: cmd.Bool("wordcount"),
-	}
-	_, counts, err := binder.AssembleMarkdown(config)
-	if err != nil {
-		return err
-	}
-	for _, wc := range counts {
-		fmt.Println(binder.FormatWordCount(wc))
+		// Watching always counts words so each rebuild can report the total.
+		WordCount: cmd.Bool("wordcount") || cmd.Bool("watch"),
+		Force:     cmd.Bool("force"),
+		Drafts:    cmd.Bool("drafts"),
+		Critic:    cmd.String("critic"),
 	}
-	return nil
+	return assemble(ctx, cmd, config.OutputDir, func() error {
+		_, counts, err := binder.AssembleMarkdown(config)
+		if errors.Is(err, binder.ErrUnmanagedOutputDir) {
+			return fmt.Errorf("%w (use --force to assemble into it anyway)", err)
+		}
+		if err != nil {
+			return err
+		}
+		total := 0
+		for _, wc := range counts {
+			if cmd.Bool("wordcount") {
+				fmt.Println(binder.FormatWordCount(wc))
+			}
+			total += wc.Count
+		}
+		if cmd.Bool("watch") {
+			fmt.Printf("total: %s words\n", binder.FormatThousands(total))
+		}
+		return nil
+	})
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+var docxCommand = &cli.Command{
+	Name:   "docx",
+	Usage:  "assemble book as a standard manuscript format .docx file",
+	Action: docx,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:      "output",
+			TakesFile: true,
+			Aliases:   []string{"o"},
+			Usage:     "output .docx file",
+			Required:  true,
+		},
+		&cli.StringFlag{
+			Name:  "font",
+			Usage: "manuscript font: courier or times",
+			Value: "courier",
+		},
+		draftsFlag(),
+		criticFlag(),
+		watchFlag(),
+	},
+}
+
+func docx(ctx context.Context, cmd *cli.Command) error {
+	config := binder.DocxConfig{
+		InputFile:  cmd.String("input"),
+		OutputFile: cmd.String("output"),
+		Font:       cmd.String("font"),
+		Drafts:     cmd.Bool("drafts"),
+		Critic:     cmd.String("critic"),
+	}
+	return assemble(ctx, cmd, config.OutputFile, func() error {
+		_, err := binder.AssembleDocx(config)
+		return err
+	})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+var epubCommand = &cli.Command{
+	Name:   "epub",
+	Usage:  "assemble book as an EPUB 3 file",
+	Action: epub,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:      "output",
+			TakesFile: true,
+			Aliases:   []string{"o"},
+			Usage:     "output .epub file",
+			Required:  true,
+		},
+		draftsFlag(),
+		criticFlag(),
+		watchFlag(),
+	},
+}
+
+func epub(ctx context.Context, cmd *cli.Command) error {
+	config := binder.EpubConfig{
+		InputFile:  cmd.String("input"),
+		OutputFile: cmd.String("output"),
+		Drafts:     cmd.Bool("drafts"),
+		Critic:     cmd.String("critic"),
+	}
+	return assemble(ctx, cmd, config.OutputFile, func() error {
+		_, err := binder.AssembleEpub(config)
+		return err
+	})
+}
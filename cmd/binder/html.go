@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+var htmlCommand = &cli.Command{
+	Name:   "html",
+	Usage:  "assemble book as a single self-contained HTML file",
+	Action: html,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:      "output",
+			TakesFile: true,
+			Aliases:   []string{"o"},
+			Usage:     "output .html file",
+			Required:  true,
+		},
+		draftsFlag(),
+		criticFlag(),
+		watchFlag(),
+	},
+}
+
+func html(ctx context.Context, cmd *cli.Command) error {
+	config := binder.HTMLConfig{
+		InputFile:  cmd.String("input"),
+		OutputFile: cmd.String("output"),
+		Drafts:     cmd.Bool("drafts"),
+		Critic:     cmd.String("critic"),
+	}
+	return assemble(ctx, cmd, config.OutputFile, func() error {
+		_, err := binder.AssembleHTML(config)
+		return err
+	})
+}
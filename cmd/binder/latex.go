@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+var latexCommand = &cli.Command{
+	Name:    "latex",
+	Usage:   "assemble book as a LaTeX document for print interiors",
+	Aliases: []string{"tex"},
+	Action:  latex,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:      "output",
+			TakesFile: true,
+			Aliases:   []string{"o"},
+			Usage:     "output .tex file",
+			Required:  true,
+		},
+		draftsFlag(),
+		criticFlag(),
+		watchFlag(),
+	},
+}
+
+func latex(ctx context.Context, cmd *cli.Command) error {
+	config := binder.LatexConfig{
+		InputFile:  cmd.String("input"),
+		OutputFile: cmd.String("output"),
+		Drafts:     cmd.Bool("drafts"),
+		Critic:     cmd.String("critic"),
+	}
+	return assemble(ctx, cmd, config.OutputFile, func() error {
+		_, err := binder.AssembleLatex(config)
+		return err
+	})
+}
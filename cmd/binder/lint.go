@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+var lintCommand = &cli.Command{
+	Name:   "lint",
+	Usage:  "check the book spec and manuscript for problems",
+	Action: lint,
+}
+
+func lint(ctx context.Context, cmd *cli.Command) error {
+	issues, err := binder.LintBook(cmd.String("input"))
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	switch len(issues) {
+	case 0:
+		return nil
+	case 1:
+		return cli.Exit("1 problem found", 1)
+	default:
+		return cli.Exit(fmt.Sprintf("%d problems found", len(issues)), 1)
+	}
+}
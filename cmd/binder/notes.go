@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+var notesCommand = &cli.Command{
+	Name:   "notes",
+	Usage:  "list author notes, comments and TK placeholders left in scenes",
+	Action: notes,
+}
+
+func notes(ctx context.Context, cmd *cli.Command) error {
+	found, err := binder.BookNotes(cmd.String("input"))
+	if err != nil {
+		return err
+	}
+	for _, note := range found {
+		fmt.Println(note)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+var progressCommand = &cli.Command{
+	Name:  "progress",
+	Usage: "track words written over time",
+	Commands: []*cli.Command{
+		{
+			Name:   "record",
+			Usage:  "append a snapshot of per-scene word counts to the book's history",
+			Action: progressRecord,
+		},
+		{
+			Name:   "report",
+			Usage:  "show words written per day and week, streaks and changed scenes",
+			Action: progressReport,
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "days",
+					Usage: "number of recent days to list, 0 for all",
+					Value: 14,
+				},
+			},
+		},
+	},
+}
+
+func progressRecord(ctx context.Context, cmd *cli.Command) error {
+	input := cmd.String("input")
+	snapshot, err := binder.RecordProgress(input, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("recorded %s words in %s\n", binder.FormatThousands(snapshot.Words), binder.ProgressFile(input))
+	return nil
+}
+
+func progressReport(ctx context.Context, cmd *cli.Command) error {
+	snapshots, err := binder.LoadProgress(cmd.String("input"))
+	if err != nil {
+		return err
+	}
+	return binder.BuildProgressReport(snapshots, time.Now()).Write(os.Stdout, int(cmd.Int("days")))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+var serveCommand = &cli.Command{
+	Name:   "serve",
+	Usage:  "preview the book in a browser, reloading as scenes change",
+	Action: serve,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "addr",
+			Aliases: []string{"a"},
+			Usage:   "address to listen on",
+			Value:   "localhost:8080",
+		},
+		draftsFlag(),
+		criticFlag(),
+	},
+}
+
+func serve(ctx context.Context, cmd *cli.Command) error {
+	preview := binder.NewPreviewServer(cmd.String("input"))
+	preview.Drafts = cmd.Bool("drafts")
+	preview.Critic = cmd.String("critic")
+	if err := preview.Rebuild(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+	}
+	listener, err := net.Listen("tcp", cmd.String("addr"))
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: preview}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	fmt.Fprintf(os.Stderr, "serving %s at http://%s/, press Ctrl-C to stop\n", preview.InputFile, listener.Addr())
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	watcher := &binder.Watcher{InputFile: preview.InputFile}
+	go func() {
+		_ = watcher.Run(ctx, func() {
+			if err := preview.Rebuild(); err != nil {
+				fmt.Fprintln(os.Stderr, "error:", err)
+			}
+		})
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+	// Event streams never go idle, so close rather than shut down gracefully.
+	if err := server.Close(); err != nil {
+		return err
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
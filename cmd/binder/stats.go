@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+var statsCommand = &cli.Command{
+	Name:   "stats",
+	Usage:  "report word counts against targets per chapter, part and book",
+	Action: stats,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage:   "output format: table, json or csv",
+			Value:   binder.StatsTable,
+		},
+		&cli.StringFlag{
+			Name:    "count",
+			Aliases: []string{"c"},
+			Usage:   "word counting mode: plain, markdown or estimate (default: the book's word_count)",
+		},
+	},
+}
+
+func stats(ctx context.Context, cmd *cli.Command) error {
+	bookStats, err := binder.CollectStats(cmd.String("input"), cmd.String("count"))
+	if err != nil {
+		return err
+	}
+	return bookStats.Write(os.Stdout, cmd.String("format"))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/poiesic/binder"
+	"github.com/urfave/cli/v3"
+)
+
+// watchFlag returns the --watch flag shared by the assembling commands.
+func watchFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "watch",
+		Usage: "re-assemble whenever the book yaml or its scenes change",
+	}
+}
+
+// assemble runs build once and, when --watch is set, again after every
+// change to the book until interrupted. Errors while watching are reported
+// without stopping the watch.
+func assemble(ctx context.Context, cmd *cli.Command, output string, build func() error) error {
+	if !cmd.Bool("watch") {
+		return build()
+	}
+	if err := build(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	watcher := &binder.Watcher{
+		InputFile: cmd.String("input"),
+		Ignore:    []string{output},
+	}
+	fmt.Fprintf(os.Stderr, "watching %s for changes, press Ctrl-C to stop\n", watcher.InputFile)
+	return watcher.Run(ctx, func() {
+		if err := build(); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "assembled %s at %s\n", output, time.Now().Format(time.TimeOnly))
+	})
+}
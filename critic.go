@@ -0,0 +1,158 @@
+package binder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CriticMarkup modes, choosing which side of each editorial change a build
+// shows.
+const (
+	// CriticAccept applies every change: insertions are kept, deletions
+	// dropped and substitutions take their new text.
+	CriticAccept = "accept"
+	// CriticReject undoes every change, giving the text before editing.
+	CriticReject = "reject"
+	// CriticReview shows the changes themselves: markdown keeps the
+	// CriticMarkup, HTML and EPUB mark them up as insertions, deletions and
+	// highlights, and DOCX records them as tracked changes and comments.
+	CriticReview = "review"
+)
+
+// ValidateCriticMode checks that mode is a known CriticMarkup mode. The
+// empty mode is CriticAccept.
+func ValidateCriticMode(mode string) error {
+	switch mode {
+	case "", CriticAccept, CriticReject, CriticReview:
+		return nil
+	default:
+		return fmt.Errorf("unknown CriticMarkup mode %q (want accept, reject or review)", mode)
+	}
+}
+
+// Change marks a span of text as part of an editorial change in a review
+// copy.
+type Change int
+
+const (
+	Unchanged Change = iota
+	Inserted
+	Deleted
+	Highlighted
+	EditorComment
+)
+
+// criticPattern matches the CriticMarkup changes other than comments, which
+// are notes: {~~old~>new~~}, {++insertion++}, {--deletion--} and
+// {==highlight==}. Code is matched too, as in notePattern, so that changes
+// written in code are left as they are.
+var criticPattern = regexp.MustCompile("(?s)```.*?```|`[^`]*`|" + `\{~~(.*?)~>(.*?)~~\}|\{\+\+(.*?)\+\+\}|\{--(.*?)--\}|\{==(.*?)==\}`)
+
+// ApplyCritic resolves the CriticMarkup changes in text as mode asks,
+// accepting or rejecting them; highlights keep their text. Text is returned
+// unchanged in CriticReview mode. Comments are left for StripNotes. Where a
+// change leaves nothing behind, the space beside it goes too.
+func ApplyCritic(text, mode string) string {
+	if mode == CriticReview {
+		return text
+	}
+	accept := mode != CriticReject
+	matches := criticPattern.FindAllStringSubmatchIndex(text, -1)
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		if isCode(text[m[0]:m[1]]) {
+			continue
+		}
+		group := func(n int) string {
+			if m[2*n] < 0 {
+				return ""
+			}
+			return text[m[2*n]:m[2*n+1]]
+		}
+		var kept string
+		switch {
+		case m[2] >= 0: // substitution
+			kept = group(1)
+			if accept {
+				kept = group(2)
+			}
+		case m[6] >= 0: // insertion
+			if accept {
+				kept = group(3)
+			}
+		case m[8] >= 0: // deletion
+			if !accept {
+				kept = group(4)
+			}
+		default: // highlight
+			kept = group(5)
+		}
+		start, end := m[0], m[1]
+		if kept == "" {
+			atLineStart := start == 0 || text[start-1] == '\n'
+			switch {
+			case start > last && text[start-1] == ' ' && (end == len(text) || strings.ContainsRune(" \n.,;:!?)", rune(text[end]))):
+				start--
+			case atLineStart && end < len(text) && text[end] == ' ':
+				end++
+			}
+		}
+		sb.WriteString(text[last:start])
+		sb.WriteString(kept)
+		last = end
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// criticMarkers are the inline CriticMarkup delimiters parseInline turns
+// into changed spans.
+var criticMarkers = []struct {
+	open, close string
+	change      Change
+}{
+	{"{++", "++}", Inserted},
+	{"{--", "--}", Deleted},
+	{"{~~", "~~}", Deleted}, // a substitution, split into deleted and inserted text
+	{"{==", "==}", Highlighted},
+	{"{>>", "<<}", EditorComment},
+}
+
+// parseCritic parses a CriticMarkup change at the start of text, returning
+// its spans and length. ok is false when text does not start with a
+// complete change.
+func parseCritic(text string, italic, bold bool) (spans []Span, n int, ok bool) {
+	for _, marker := range criticMarkers {
+		if !strings.HasPrefix(text, marker.open) {
+			continue
+		}
+		end := strings.Index(text[len(marker.open):], marker.close)
+		if end < 0 {
+			return nil, 0, false
+		}
+		inner := text[len(marker.open) : len(marker.open)+end]
+		n = len(marker.open) + end + len(marker.close)
+		mark := func(text string, change Change) {
+			for _, span := range parseInline(text) {
+				span.Italic = span.Italic || italic
+				span.Bold = span.Bold || bold
+				span.Change = change
+				spans = append(spans, span)
+			}
+		}
+		switch {
+		case marker.change == EditorComment:
+			spans = []Span{{Text: strings.TrimSpace(inner), Change: EditorComment}}
+		case marker.open == "{~~":
+			old, replacement, _ := strings.Cut(inner, "~>")
+			mark(old, Deleted)
+			mark(replacement, Inserted)
+		default:
+			mark(inner, marker.change)
+		}
+		return spans, n, true
+	}
+	return nil, 0, false
+}
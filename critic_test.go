@@ -0,0 +1,106 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const criticText = "She {++quietly ++}left the {--old --}house {~~at dawn~>before dawn~~}. {==It rained.==}{>>Too abrupt?<<}"
+
+func TestApplyCritic(t *testing.T) {
+	assert.Equal(t, "She quietly left the house before dawn. It rained.{>>Too abrupt?<<}", ApplyCritic(criticText, CriticAccept))
+	assert.Equal(t, "She quietly left the house before dawn. It rained.{>>Too abrupt?<<}", ApplyCritic(criticText, ""))
+	assert.Equal(t, "She left the old house at dawn. It rained.{>>Too abrupt?<<}", ApplyCritic(criticText, CriticReject))
+	assert.Equal(t, criticText, ApplyCritic(criticText, CriticReview))
+}
+
+func TestApplyCritic_ClosesUpSpace(t *testing.T) {
+	assert.Equal(t, "a word.", ApplyCritic("a word {--here--}.", CriticAccept))
+	assert.Equal(t, "a c", ApplyCritic("a {++b++} c", CriticReject))
+	assert.Equal(t, "line two", ApplyCritic("line {++one\nline++} two", CriticReject))
+	assert.Equal(t, "Start here.", ApplyCritic("{--Gone--} Start here.", CriticAccept))
+}
+
+func TestApplyCritic_SkipsCode(t *testing.T) {
+	text := "Type `{--x--}` for {--a--}{++the++} deletion.\n\n```\n{~~a~>b~~} {++c++}\n```\n"
+	assert.Equal(t, "Type `{--x--}` for the deletion.\n\n```\n{~~a~>b~~} {++c++}\n```\n", ApplyCritic(text, CriticAccept))
+	assert.Equal(t, "Type `{--x--}` for a deletion.\n\n```\n{~~a~>b~~} {++c++}\n```\n", ApplyCritic(text, CriticReject))
+}
+
+func TestValidateCriticMode(t *testing.T) {
+	assert.NoError(t, ValidateCriticMode(""))
+	assert.NoError(t, ValidateCriticMode(CriticReview))
+	assert.ErrorContains(t, ValidateCriticMode("merge"), `unknown CriticMarkup mode "merge"`)
+}
+
+func TestParseInline_Critic(t *testing.T) {
+	spans := parseInline("a {++*new*++} b {~~x~>y~~} {==hi==}{>> note <<}")
+	assert.Equal(t, []Span{
+		{Text: "a "},
+		{Text: "new", Italic: true, Change: Inserted},
+		{Text: " b "},
+		{Text: "x", Change: Deleted},
+		{Text: "y", Change: Inserted},
+		{Text: " "},
+		{Text: "hi", Change: Highlighted},
+		{Text: "note", Change: EditorComment},
+	}, spans)
+
+	// Unclosed markup is literal text.
+	assert.Equal(t, []Span{{Text: "a {++b"}}, parseInline("a {++b"))
+}
+
+func TestWriteHTMLSpans_Critic(t *testing.T) {
+	var sb strings.Builder
+	writeHTMLSpans(&sb, parseInline(criticText))
+	assert.Equal(t, `She <ins>quietly </ins>left the <del>old </del>house <del>at dawn</del><ins>before dawn</ins>. `+
+		`<mark>It rained.</mark><span class="critic-comment">Too abrupt?</span>`, sb.String())
+}
+
+func TestSceneOptions_Critic(t *testing.T) {
+	scene := &Scene{Body: "{++New++} text [[fix]] {>>why?<<}"}
+	assert.Equal(t, "New text", SceneOptions{}.body(scene))
+	assert.Equal(t, "text", SceneOptions{Critic: CriticReject}.body(scene))
+	assert.Equal(t, "{++New++} text {>>why?<<}", SceneOptions{Critic: CriticReview}.body(scene))
+	assert.Equal(t, "New text [[fix]] {>>why?<<}", SceneOptions{Drafts: true}.body(scene))
+}
+
+func TestAssembleDocx_CriticReview(t *testing.T) {
+	root := copyManuscript(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "manuscript", "foo.md"), []byte(criticText+"\n"), 0644))
+	output := filepath.Join(root, "review.docx")
+	_, err := AssembleDocx(DocxConfig{InputFile: filepath.Join(root, "book.yaml"), OutputFile: output, Critic: CriticReview})
+	require.NoError(t, err)
+
+	document := readZipEntry(t, output, "word/document.xml")
+	assert.Contains(t, document, `<w:r><w:t xml:space="preserve">quietly </w:t></w:r></w:ins>`)
+	assert.Contains(t, document, `<w:r><w:delText xml:space="preserve">old </w:delText></w:r></w:del>`)
+	assert.Contains(t, document, `<w:highlight w:val="yellow"/>`)
+	assert.Contains(t, document, `<w:commentRangeStart w:id="5"/>`)
+	assert.Contains(t, readZipEntry(t, output, "word/comments.xml"), `<w:t xml:space="preserve">Too abrupt?</w:t>`)
+	assert.Contains(t, readZipEntry(t, output, "[Content_Types].xml"), "/word/comments.xml")
+	assert.Contains(t, readZipEntry(t, output, "word/_rels/document.xml.rels"), "comments.xml")
+
+	// A clean build has no tracked changes or comments.
+	_, err = AssembleDocx(DocxConfig{InputFile: filepath.Join(root, "book.yaml"), OutputFile: output})
+	require.NoError(t, err)
+	document = readZipEntry(t, output, "word/document.xml")
+	assert.NotContains(t, document, "<w:ins")
+	assert.Contains(t, document, "before dawn")
+	assert.NotContains(t, readZipEntry(t, output, "[Content_Types].xml"), "comments")
+}
+
+func TestAssembleLatex_CriticReview(t *testing.T) {
+	_, err := AssembleLatex(LatexConfig{InputFile: "testdata/valid_book.yaml", OutputFile: filepath.Join(t.TempDir(), "book.tex"), Critic: CriticReview})
+	assert.ErrorContains(t, err, "no review copy")
+}
+
+func TestAssembleMarkdown_UnknownCriticMode(t *testing.T) {
+	_, _, err := AssembleMarkdown(AssemblyConfig{InputFile: "testdata/valid_book.yaml", OutputDir: t.TempDir(), Critic: "merge"})
+	assert.Error(t, err)
+}
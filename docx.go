@@ -0,0 +1,547 @@
+package binder
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DocxConfig holds the parameters for assembling a manuscript as a single
+// .docx file in standard manuscript format.
+type DocxConfig struct {
+	InputFile  string
+	OutputFile string
+	Font       string // "courier" (default) or "times"
+	Drafts     bool   // keep author notes in the output
+	Critic     string // CriticAccept (default), CriticReject or CriticReview
+}
+
+// Page geometry in twentieths of a point (twips) for US Letter with 1" margins.
+const (
+	docxPageWidth  = 12240
+	docxPageHeight = 15840
+	docxMargin     = 1440
+	docxTextWidth  = docxPageWidth - 2*docxMargin
+	// Chapters start one-third of the way down the page.
+	docxChapterDrop = docxPageHeight/3 - docxMargin
+	// The title sits roughly halfway down the first page, below the contact block.
+	docxTitleDrop = docxPageHeight/2 - docxMargin - 5*240
+)
+
+var docxFonts = map[string]string{
+	"courier": "Courier New",
+	"times":   "Times New Roman",
+}
+
+// AssembleDocx assembles a book into a single .docx file laid out in
+// Shunn-style standard manuscript format. Returns the parsed FrontMatter.
+func AssembleDocx(config DocxConfig) (*FrontMatter, error) {
+	fontKey := config.Font
+	if fontKey == "" {
+		fontKey = "courier"
+	}
+	font, ok := docxFonts[fontKey]
+	if !ok {
+		return nil, fmt.Errorf("unsupported docx font %q (want courier or times)", config.Font)
+	}
+	if err := ValidateCriticMode(config.Critic); err != nil {
+		return nil, err
+	}
+	frontMatter, book, err := LoadBook(config.InputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &docxBody{}
+	words := 0
+	ended := false
+	options := book.sceneOptions()
+	options.Drafts = config.Drafts
+	options.Critic = config.Critic
+	notes := newFootnoter(book)
+	var bookNotes []footnote
+	for chapter := range book.GetChapters() {
+		if err := chapter.Validate(); err != nil {
+			return nil, err
+		}
+		if chapter.Matter == BackMatterSection && !ended {
+			body.end()
+			ended = true
+		}
+		if chapter.StartsNamedPart() {
+			body.partPage(chapter.Part)
+		}
+		body.chapterHeading(chapter.Heading)
+		body.sceneBreakText = chapter.SceneBreak.docx()
+		notes.startChapter(chapter.Label())
+		var chapterNotes []footnote
+		scenes, err := chapter.LoadScenes()
+		if err != nil {
+			return nil, err
+		}
+		for i := range scenes {
+			text := &scenes[i]
+			// Only the story itself counts toward the manuscript word count.
+			if chapter.Matter == BodyMatter {
+				wc, err := CountWords(text.Body, book.WordCount)
+				if err != nil {
+					return nil, err
+				}
+				words += wc
+			}
+			if i > 0 {
+				body.sceneBreak()
+			}
+			sceneText, sceneNotes := notes.scene(options.body(text))
+			chapterNotes = append(chapterNotes, sceneNotes...)
+			if !notes.endnotes() {
+				body.footnotes = footnotesByID(sceneNotes)
+			}
+			body.blocks(ParseMarkdown(sceneText))
+		}
+		switch {
+		case notes.mode == FootnotesChapter && len(chapterNotes) > 0:
+			body.paragraph("SceneHeading", "", []Span{{Text: notes.heading}})
+			body.notes(chapterNotes)
+		case notes.mode == FootnotesBook:
+			bookNotes = append(bookNotes, chapterNotes...)
+		}
+	}
+	if !ended {
+		body.end()
+	}
+	if len(bookNotes) > 0 {
+		body.chapterHeading(notes.heading)
+		for _, group := range groupByChapter(bookNotes) {
+			body.paragraph("SceneHeading", "", []Span{{Text: group[0].label}})
+			body.notes(group)
+		}
+	}
+
+	// The title page carries the word count, so it is built last and
+	// placed in front of the chapters.
+	document := &docxBody{}
+	document.titlePage(frontMatter, words)
+	document.buf.Write(body.buf.Bytes())
+
+	err = writeFileAtomic(config.OutputFile, func(fd *os.File) error {
+		return writeDocx(fd, frontMatter, font, document.buf.Bytes(), body.comments.Bytes(), body.footnoteXML.Bytes())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return frontMatter, nil
+}
+
+// ApproximateWordCount rounds a word count the way manuscript submissions
+// expect: to the nearest hundred for short works and the nearest thousand
+// for anything of novelette length or longer.
+func ApproximateWordCount(count int) int {
+	unit := 100
+	if count >= 10000 {
+		unit = 1000
+	}
+	rounded := (count + unit/2) / unit * unit
+	if rounded == 0 && count > 0 {
+		rounded = unit
+	}
+	return rounded
+}
+
+// FormatThousands formats n with comma thousands separators.
+func FormatThousands(n int) string {
+	if n < 0 {
+		return "-" + FormatThousands(-n)
+	}
+	s := fmt.Sprintf("%d", n)
+	var sb strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// runningHeader returns the "Lastname / Short Title / " prefix of the page
+// header, falling back to the last word of the author and the full title.
+func runningHeader(fm *FrontMatter) string {
+	lastName := fm.AuthorLastName
+	if lastName == "" {
+		if fields := strings.Fields(fm.Author); len(fields) > 0 {
+			lastName = fields[len(fields)-1]
+		}
+	}
+	shortTitle := fm.ShortTitle
+	if shortTitle == "" {
+		shortTitle = fm.Title
+	}
+	return fmt.Sprintf("%s / %s / ", lastName, shortTitle)
+}
+
+// docxBody accumulates WordprocessingML paragraphs.
+type docxBody struct {
+	buf bytes.Buffer
+	// changes numbers tracked changes and comments, which share an ID
+	// space; comments holds the <w:comment> elements for comments.xml.
+	changes  int
+	comments bytes.Buffer
+	// sceneBreakText is the text of the current chapter's scene breaks.
+	sceneBreakText string
+	// footnotes holds the notes of the current scene that are set as Word
+	// footnotes at their references; footnoteXML holds the <w:footnote>
+	// elements for footnotes.xml, numbered by footnoteCount.
+	footnotes     map[string]footnote
+	footnoteXML   bytes.Buffer
+	footnoteCount int
+}
+
+// titlePage writes the contact block with the approximate word count at
+// the right of its first line, or on a line of its own when there is no
+// contact information, followed by the title and author.
+func (d *docxBody) titlePage(fm *FrontMatter, words int) {
+	contact := []string{fm.ContactName, fm.ContactAddress, fm.ContactCityStateZip, fm.ContactPhone, fm.ContactEmail}
+	wordCount := func() {
+		d.buf.WriteString(`<w:r><w:tab/></w:r>`)
+		d.run(Span{Text: fmt.Sprintf("about %s words", FormatThousands(ApproximateWordCount(words)))})
+	}
+	first := true
+	for _, line := range contact {
+		if line == "" {
+			continue
+		}
+		d.buf.WriteString(`<w:p><w:pPr><w:pStyle w:val="Contact"/></w:pPr>`)
+		d.run(Span{Text: line})
+		if first {
+			wordCount()
+			first = false
+		}
+		d.buf.WriteString(`</w:p>`)
+	}
+	if first {
+		d.buf.WriteString(`<w:p><w:pPr><w:pStyle w:val="Contact"/></w:pPr>`)
+		wordCount()
+		d.buf.WriteString(`</w:p>`)
+	}
+	d.paragraph("Title", fmt.Sprintf(`<w:spacing w:before="%d"/>`, docxTitleDrop), []Span{{Text: fm.Title}})
+	if fm.Author != "" {
+		d.paragraph("Title", "", []Span{{Text: "by " + fm.Author}})
+	}
+}
+
+func (d *docxBody) partPage(part *IteratedPart) {
+	props := fmt.Sprintf(`<w:pageBreakBefore/><w:spacing w:before="%d"/>`, docxTitleDrop)
+	d.paragraph("PartHeading", props, []Span{{Text: part.Heading}})
+	for _, block := range ParseMarkdown(part.Text) {
+		d.paragraph("Title", "", block.Spans)
+	}
+}
+
+func (d *docxBody) chapterHeading(heading string) {
+	props := fmt.Sprintf(`<w:pageBreakBefore/><w:spacing w:before="%d"/>`, docxChapterDrop)
+	var spans []Span
+	if heading != "" {
+		spans = []Span{{Text: heading}}
+	}
+	d.paragraph("ChapterHeading", props, spans)
+}
+
+// end marks the end of the story, before any back matter.
+func (d *docxBody) end() {
+	d.paragraph("SceneBreak", "", []Span{{Text: "END"}})
+}
+
+func (d *docxBody) sceneBreak() {
+	d.paragraph("SceneBreak", "", []Span{{Text: d.sceneBreakText}})
+}
+
+func (d *docxBody) blocks(blocks []Block) {
+	for _, block := range blocks {
+		switch block.Kind {
+		case HeadingBlock:
+			d.paragraph("SceneHeading", "", block.Spans)
+		case QuoteBlock:
+			d.paragraph("Quote", "", block.Spans)
+		case SceneBreakBlock:
+			d.sceneBreak()
+		default:
+			d.paragraph("Body", "", block.Spans)
+		}
+	}
+}
+
+func (d *docxBody) paragraph(style, props string, spans []Span) {
+	fmt.Fprintf(&d.buf, `<w:p><w:pPr><w:pStyle w:val="%s"/>%s</w:pPr>`, style, props)
+	for i := 0; i < len(spans); i++ {
+		// An editor comment becomes a Word comment on the highlighted text
+		// before it, or on the point where it was made.
+		if spans[i].Change == EditorComment {
+			d.comment(spans[i].Text, nil)
+			continue
+		}
+		if spans[i].Change == Highlighted {
+			end := i
+			for end < len(spans) && spans[end].Change == Highlighted {
+				end++
+			}
+			if end < len(spans) && spans[end].Change == EditorComment {
+				d.comment(spans[end].Text, spans[i:end])
+				i = end
+				continue
+			}
+		}
+		d.run(spans[i])
+	}
+	d.buf.WriteString(`</w:p>`)
+}
+
+// comment writes a Word comment anchored on the given spans.
+func (d *docxBody) comment(text string, anchor []Span) {
+	d.changes++
+	id := d.changes
+	fmt.Fprintf(&d.buf, `<w:commentRangeStart w:id="%d"/>`, id)
+	for _, span := range anchor {
+		d.run(span)
+	}
+	fmt.Fprintf(&d.buf, `<w:commentRangeEnd w:id="%d"/><w:r><w:commentReference w:id="%d"/></w:r>`, id, id)
+	fmt.Fprintf(&d.comments, `<w:comment w:id="%d" w:author="%s"><w:p><w:r><w:t xml:space="preserve">%s</w:t></w:r></w:p></w:comment>`,
+		id, docxReviewer, xmlEscape(text))
+}
+
+// footnote writes a Word footnote holding text, referenced at the current
+// point.
+func (d *docxBody) footnote(text string) {
+	d.footnoteCount++
+	id := d.footnoteCount
+	fmt.Fprintf(&d.buf, `<w:r><w:rPr><w:rStyle w:val="FootnoteReference"/></w:rPr><w:footnoteReference w:id="%d"/></w:r>`, id)
+	// The note's paragraphs are built in a body of their own that shares
+	// the change numbering.
+	note := &docxBody{changes: d.changes}
+	fmt.Fprintf(&note.buf, `<w:footnote w:id="%d">`, id)
+	for i, block := range ParseMarkdown(text) {
+		note.buf.WriteString(`<w:p><w:pPr><w:pStyle w:val="FootnoteText"/></w:pPr>`)
+		if i == 0 {
+			note.buf.WriteString(`<w:r><w:rPr><w:rStyle w:val="FootnoteReference"/></w:rPr><w:footnoteRef/></w:r><w:r><w:t xml:space="preserve"> </w:t></w:r>`)
+		}
+		for _, span := range block.Spans {
+			note.run(span)
+		}
+		note.buf.WriteString(`</w:p>`)
+	}
+	note.buf.WriteString(`</w:footnote>`)
+	d.changes = note.changes
+	d.footnoteXML.Write(note.buf.Bytes())
+}
+
+// notes writes collected notes as numbered paragraphs.
+func (d *docxBody) notes(notes []footnote) {
+	for _, note := range notes {
+		for i, block := range ParseMarkdown(note.text) {
+			spans := block.Spans
+			if i == 0 {
+				spans = append([]Span{{Text: fmt.Sprintf("%d. ", note.number)}}, spans...)
+			}
+			d.paragraph("Note", "", spans)
+		}
+	}
+}
+
+// docxReviewer is the author recorded on tracked changes and comments.
+const docxReviewer = "Editor"
+
+func (d *docxBody) run(span Span) {
+	if note, ok := d.footnotes[span.Note]; ok && span.Note != "" {
+		d.footnote(note.text)
+		return
+	}
+	switch span.Change {
+	case Inserted:
+		d.changes++
+		fmt.Fprintf(&d.buf, `<w:ins w:id="%d" w:author="%s">`, d.changes, docxReviewer)
+		defer d.buf.WriteString(`</w:ins>`)
+	case Deleted:
+		d.changes++
+		fmt.Fprintf(&d.buf, `<w:del w:id="%d" w:author="%s">`, d.changes, docxReviewer)
+		defer d.buf.WriteString(`</w:del>`)
+	}
+	d.buf.WriteString(`<w:r>`)
+	if span.Italic || span.Bold || span.Change == Highlighted || span.Note != "" {
+		d.buf.WriteString(`<w:rPr>`)
+		if span.Bold {
+			d.buf.WriteString(`<w:b/>`)
+		}
+		if span.Italic {
+			d.buf.WriteString(`<w:i/>`)
+		}
+		if span.Change == Highlighted {
+			d.buf.WriteString(`<w:highlight w:val="yellow"/>`)
+		}
+		if span.Note != "" {
+			d.buf.WriteString(`<w:vertAlign w:val="superscript"/>`)
+		}
+		d.buf.WriteString(`</w:rPr>`)
+	}
+	// Deleted text must be marked as such for Word to accept the change.
+	text := "w:t"
+	if span.Change == Deleted {
+		text = "w:delText"
+	}
+	fmt.Fprintf(&d.buf, `<%s xml:space="preserve">`, text)
+	_ = xml.EscapeText(&d.buf, []byte(span.Text))
+	fmt.Fprintf(&d.buf, `</%s></w:r>`, text)
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// writeDocx writes the package parts of a .docx file with the given
+// document body to fd. Comments and footnotes parts are only included when
+// there are comments and footnotes.
+func writeDocx(fd *os.File, fm *FrontMatter, font string, body, comments, footnotes []byte) error {
+	header := fmt.Sprintf(docxHeaderXML, xmlEscape(runningHeader(fm)))
+	document := fmt.Sprintf(docxDocumentXML, body, docxPageWidth, docxPageHeight, docxMargin, docxMargin, docxMargin, docxMargin)
+	contentTypes, documentRels := "", ""
+	if len(comments) > 0 {
+		contentTypes, documentRels = docxCommentsContentType, docxCommentsRel
+	}
+	if len(footnotes) > 0 {
+		contentTypes += docxFootnotesContentType
+		documentRels += docxFootnotesRel
+	}
+	parts := []zipPart{
+		{"[Content_Types].xml", fmt.Sprintf(docxContentTypesXML, contentTypes)},
+		{"_rels/.rels", docxRootRelsXML},
+		{"docProps/core.xml", fmt.Sprintf(docxCoreXML, xmlEscape(fm.Title), xmlEscape(fm.Author))},
+		{"word/_rels/document.xml.rels", fmt.Sprintf(docxDocumentRelsXML, documentRels)},
+		{"word/styles.xml", fmt.Sprintf(docxStylesXML, font, font, font, font, xmlEscape(EpubLanguage(fm)), docxTextWidth)},
+		{"word/header1.xml", header},
+		{"word/header2.xml", docxEmptyHeaderXML},
+		{"word/document.xml", document},
+	}
+	if len(comments) > 0 {
+		parts = append(parts, zipPart{"word/comments.xml", fmt.Sprintf(docxCommentsXML, comments)})
+	}
+	if len(footnotes) > 0 {
+		parts = append(parts, zipPart{"word/footnotes.xml", fmt.Sprintf(docxFootnotesXML, footnotes)})
+	}
+	zw := zip.NewWriter(fd)
+	if err := writeZipParts(zw, parts); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// zipPart is a named file inside a zip-based package such as .docx or .epub.
+type zipPart struct {
+	name    string
+	content string
+}
+
+func writeZipParts(zw *zip.Writer, parts []zipPart) error {
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const docxContentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>
+<Override PartName="/word/header2.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>
+<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>
+%s</Types>`
+
+const docxCommentsContentType = `<Override PartName="/word/comments.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.comments+xml"/>
+`
+
+const docxFootnotesContentType = `<Override PartName="/word/footnotes.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footnotes+xml"/>
+`
+
+const docxRootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>
+</Relationships>`
+
+const docxCoreXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:title>%s</dc:title>
+<dc:creator>%s</dc:creator>
+</cp:coreProperties>`
+
+const docxDocumentRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header2.xml"/>
+%s</Relationships>`
+
+const docxCommentsRel = `<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments" Target="comments.xml"/>
+`
+
+const docxFootnotesRel = `<Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footnotes" Target="footnotes.xml"/>
+`
+
+const docxCommentsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">%s</w:comments>`
+
+// docxFootnotesXML holds the footnotes after the separator lines Word
+// expects ahead of them.
+const docxFootnotesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:footnotes xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:footnote w:type="separator" w:id="-1"><w:p><w:pPr><w:spacing w:line="240" w:lineRule="auto"/></w:pPr><w:r><w:separator/></w:r></w:p></w:footnote>
+<w:footnote w:type="continuationSeparator" w:id="0"><w:p><w:pPr><w:spacing w:line="240" w:lineRule="auto"/></w:pPr><w:r><w:continuationSeparator/></w:r></w:p></w:footnote>
+%s</w:footnotes>`
+
+const docxDocumentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<w:body>%s<w:sectPr><w:headerReference w:type="default" r:id="rId2"/><w:headerReference w:type="first" r:id="rId3"/><w:pgSz w:w="%d" w:h="%d"/><w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="720" w:footer="720" w:gutter="0"/><w:titlePg/></w:sectPr></w:body>
+</w:document>`
+
+const docxHeaderXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:p><w:pPr><w:pStyle w:val="Header"/></w:pPr><w:r><w:t xml:space="preserve">%s</w:t></w:r><w:fldSimple w:instr=" PAGE "><w:r><w:t>1</w:t></w:r></w:fldSimple></w:p>
+</w:hdr>`
+
+const docxEmptyHeaderXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:p><w:pPr><w:pStyle w:val="Header"/></w:pPr></w:p>
+</w:hdr>`
+
+const docxStylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:docDefaults>
+<w:rPrDefault><w:rPr><w:rFonts w:ascii="%s" w:hAnsi="%s" w:cs="%s" w:eastAsia="%s"/><w:sz w:val="24"/><w:szCs w:val="24"/><w:lang w:val="%s"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:before="0" w:after="0" w:line="480" w:lineRule="auto"/></w:pPr></w:pPrDefault>
+</w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+<w:style w:type="paragraph" w:styleId="Body"><w:name w:val="Body Text"/><w:basedOn w:val="Normal"/><w:pPr><w:ind w:firstLine="720"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="Quote"><w:name w:val="Quote"/><w:basedOn w:val="Normal"/><w:pPr><w:ind w:left="720" w:right="720"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="Contact"><w:name w:val="Contact"/><w:basedOn w:val="Normal"/><w:pPr><w:tabs><w:tab w:val="right" w:pos="%d"/></w:tabs><w:spacing w:line="240" w:lineRule="auto"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="center"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="PartHeading"><w:name w:val="Part Heading"/><w:basedOn w:val="Normal"/><w:pPr><w:keepNext/><w:jc w:val="center"/><w:outlineLvl w:val="0"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="ChapterHeading"><w:name w:val="Chapter Heading"/><w:basedOn w:val="Normal"/><w:next w:val="Body"/><w:pPr><w:keepNext/><w:jc w:val="center"/><w:outlineLvl w:val="0"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="SceneHeading"><w:name w:val="Scene Heading"/><w:basedOn w:val="Normal"/><w:next w:val="Body"/><w:pPr><w:keepNext/><w:jc w:val="center"/><w:outlineLvl w:val="1"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="SceneBreak"><w:name w:val="Scene Break"/><w:basedOn w:val="Normal"/><w:next w:val="Body"/><w:pPr><w:jc w:val="center"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="Note"><w:name w:val="Note"/><w:basedOn w:val="Normal"/><w:pPr><w:ind w:left="720" w:hanging="360"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="FootnoteText"><w:name w:val="footnote text"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:line="240" w:lineRule="auto"/></w:pPr><w:rPr><w:sz w:val="20"/><w:szCs w:val="20"/></w:rPr></w:style>
+<w:style w:type="character" w:styleId="FootnoteReference"><w:name w:val="footnote reference"/><w:rPr><w:vertAlign w:val="superscript"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Header"><w:name w:val="header"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="right"/><w:spacing w:line="240" w:lineRule="auto"/></w:pPr></w:style>
+</w:styles>`
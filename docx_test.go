@@ -0,0 +1,150 @@
+package binder
+
+import (
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readZipEntry(t *testing.T, path, name string) string {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+	for _, f := range zr.File {
+		if f.Name == name {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			defer rc.Close()
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			return string(content)
+		}
+	}
+	t.Fatalf("%s not found in %s", name, path)
+	return ""
+}
+
+func TestAssembleDocx_WordCountWithoutContact(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.docx")
+	_, err := AssembleDocx(DocxConfig{InputFile: "testdata/book_with_parts.yaml", OutputFile: outFile})
+	require.NoError(t, err)
+
+	document := readZipEntry(t, outFile, "word/document.xml")
+	assert.Contains(t, document, `<w:p><w:pPr><w:pStyle w:val="Contact"/></w:pPr><w:r><w:tab/></w:r><w:r><w:t xml:space="preserve">about 100 words</w:t></w:r></w:p>`)
+}
+
+func TestAssembleDocx_ValidBook(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.docx")
+
+	config := DocxConfig{
+		InputFile:  "testdata/valid_book.yaml",
+		OutputFile: outFile,
+	}
+	fm, err := AssembleDocx(config)
+	require.NoError(t, err)
+	require.NotNil(t, fm)
+	assert.Equal(t, "Test Book", fm.Title)
+
+	document := readZipEntry(t, outFile, "word/document.xml")
+	assert.Contains(t, document, "Test Contact")
+	assert.Contains(t, document, "about 100 words")
+	assert.Contains(t, document, "by Test Author")
+	assert.Contains(t, document, "Chapter One")
+	assert.Contains(t, document, "Chapter Two")
+	assert.Contains(t, document, "This is interlude 1.")
+	assert.Contains(t, document, "This is quux.")
+	assert.Contains(t, document, `<w:t xml:space="preserve">#</w:t>`)
+
+	header := readZipEntry(t, outFile, "word/header1.xml")
+	assert.Contains(t, header, "Author / Test / ")
+	assert.Contains(t, header, "PAGE")
+
+	styles := readZipEntry(t, outFile, "word/styles.xml")
+	assert.Contains(t, styles, "Courier New")
+	assert.Contains(t, styles, `w:line="480"`)
+}
+
+func TestAssembleDocx_TimesFont(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.docx")
+
+	_, err := AssembleDocx(DocxConfig{
+		InputFile:  "testdata/valid_book.yaml",
+		OutputFile: outFile,
+		Font:       "times",
+	})
+	require.NoError(t, err)
+
+	styles := readZipEntry(t, outFile, "word/styles.xml")
+	assert.Contains(t, styles, "Times New Roman")
+}
+
+func TestAssembleDocx_UnknownFont(t *testing.T) {
+	_, err := AssembleDocx(DocxConfig{
+		InputFile:  "testdata/valid_book.yaml",
+		OutputFile: filepath.Join(t.TempDir(), "book.docx"),
+		Font:       "comic sans",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported docx font")
+}
+
+func TestAssembleDocx_MissingInput(t *testing.T) {
+	fm, err := AssembleDocx(DocxConfig{
+		InputFile:  "testdata/nonexistent.yaml",
+		OutputFile: filepath.Join(t.TempDir(), "book.docx"),
+	})
+	require.Error(t, err)
+	assert.Nil(t, fm)
+}
+
+func TestApproximateWordCount(t *testing.T) {
+	assert.Equal(t, 0, ApproximateWordCount(0))
+	assert.Equal(t, 100, ApproximateWordCount(18))
+	assert.Equal(t, 3500, ApproximateWordCount(3472))
+	assert.Equal(t, 82000, ApproximateWordCount(82345))
+}
+
+func TestFormatThousands(t *testing.T) {
+	assert.Equal(t, "0", FormatThousands(0))
+	assert.Equal(t, "999", FormatThousands(999))
+	assert.Equal(t, "82,000", FormatThousands(82000))
+	assert.Equal(t, "1,234,567", FormatThousands(1234567))
+	assert.Equal(t, "-1,000", FormatThousands(-1000))
+}
+
+func TestAssembleDocx_WithParts(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.docx")
+
+	_, err := AssembleDocx(DocxConfig{
+		InputFile:  "testdata/book_with_parts.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+
+	document := readZipEntry(t, outFile, "word/document.xml")
+	assert.Contains(t, document, `<w:pStyle w:val="PartHeading"/>`)
+	assert.Contains(t, document, "Part One: The Fall")
+	assert.Contains(t, document, "Everything falls.")
+}
+
+func TestAssembleDocx_WithMatterSections(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.docx")
+
+	_, err := AssembleDocx(DocxConfig{
+		InputFile:  "testdata/book_with_matter.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+
+	document := readZipEntry(t, outFile, "word/document.xml")
+	assert.Less(t, strings.Index(document, "For the readers."), strings.Index(document, "This is foo."))
+	// END closes the story before the back matter
+	assert.Less(t, strings.Index(document, "This is bar."), strings.Index(document, ">END<"))
+	assert.Less(t, strings.Index(document, ">END<"), strings.Index(document, "Acknowledgments"))
+}
@@ -0,0 +1,324 @@
+package binder
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// EpubConfig holds the parameters for assembling a book as an EPUB 3 file.
+type EpubConfig struct {
+	InputFile  string
+	OutputFile string
+	Drafts     bool   // keep author notes in the output
+	Critic     string // CriticAccept (default), CriticReject or CriticReview
+}
+
+// epubItem is a single XHTML content document in the EPUB package.
+type epubItem struct {
+	ID       string
+	Href     string
+	Title    string
+	Heading  string
+	EpubType string // structural semantics for the section: chapter, division or part
+	// PartStart marks the first chapter of a part without a heading and
+	// sections outside any part, such as back matter, which end the
+	// nesting of the previous part in the table of contents.
+	PartStart bool
+	Body      string
+}
+
+// AssembleEpub assembles a book into an EPUB 3 file with one XHTML document
+// per chapter. Interludes are placed in the spine but only appear in the
+// table of contents when they have a heading. Returns the parsed FrontMatter.
+func AssembleEpub(config EpubConfig) (*FrontMatter, error) {
+	if err := ValidateCriticMode(config.Critic); err != nil {
+		return nil, err
+	}
+	frontMatter, book, err := LoadBook(config.InputFile)
+	if err != nil {
+		return nil, err
+	}
+	var items []epubItem
+	options := book.sceneOptions()
+	options.Drafts = config.Drafts
+	options.Critic = config.Critic
+	notes := newFootnoter(book)
+	var bookNotes []footnote
+	cnum := 1
+	for chapter := range book.GetChapters() {
+		if err := chapter.Validate(); err != nil {
+			return nil, err
+		}
+		body, chapterNotes, err := renderChapterHTML(chapter, options, notes)
+		if err != nil {
+			return nil, err
+		}
+		if len(chapterNotes) > 0 {
+			body = linkNotes(body, "notes.xhtml")
+		}
+		if chapter.StartsNamedPart() {
+			items = append(items, epubItem{
+				ID:       fmt.Sprintf("c%03d", cnum),
+				Href:     fmt.Sprintf("text/%03d-%s.xhtml", cnum, chapter.Part.HeadingToFilename()),
+				Title:    chapter.Part.Heading,
+				EpubType: "part",
+				Body:     renderPartHTML(chapter.Part),
+			})
+			cnum += 1
+		}
+		item := epubItem{
+			ID:        fmt.Sprintf("c%03d", cnum),
+			Href:      fmt.Sprintf("text/%03d-%s.xhtml", cnum, chapter.HeadingToFilename()),
+			Title:     chapter.Label(),
+			Heading:   chapter.Heading,
+			EpubType:  epubType(chapter),
+			PartStart: chapter.PartStart && !chapter.StartsNamedPart() || chapter.Part == nil,
+			Body:      body,
+		}
+		items = append(items, item)
+		cnum += 1
+		for _, note := range chapterNotes {
+			note.page = path.Base(item.Href)
+			bookNotes = append(bookNotes, note)
+		}
+	}
+	// Notes collected for the whole book get a document of their own at
+	// the end.
+	if len(bookNotes) > 0 {
+		items = append(items, epubItem{
+			ID:        "notes",
+			Href:      "text/notes.xhtml",
+			Title:     notes.heading,
+			Heading:   notes.heading,
+			EpubType:  "endnotes",
+			PartStart: true,
+			Body:      renderNotesHTML(bookNotes),
+		})
+	}
+
+	err = writeFileAtomic(config.OutputFile, func(fd *os.File) error {
+		return writeEpub(fd, frontMatter, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return frontMatter, nil
+}
+
+// epubSectionTypes maps front and back matter section types to EPUB
+// structural semantics.
+var epubSectionTypes = map[string]string{
+	"dedication":      "dedication",
+	"epigraph":        "epigraph",
+	"copyright":       "copyright-page",
+	"acknowledgments": "acknowledgments",
+	"foreword":        "foreword",
+	"preface":         "preface",
+	"afterword":       "afterword",
+}
+
+func epubType(chapter IteratedChapter) string {
+	switch {
+	case chapter.Matter == FrontMatterSection:
+		if t, ok := epubSectionTypes[chapter.SectionType]; ok {
+			return t
+		}
+		return "frontmatter"
+	case chapter.Matter == BackMatterSection:
+		if t, ok := epubSectionTypes[chapter.SectionType]; ok {
+			return t
+		}
+		return "backmatter"
+	case chapter.Interlude:
+		return "division"
+	default:
+		return "chapter"
+	}
+}
+
+// EpubLanguage returns the book's language tag, defaulting to English.
+func EpubLanguage(fm *FrontMatter) string {
+	if fm.Language != "" {
+		return fm.Language
+	}
+	return "en"
+}
+
+// frontMatterLocale returns the locale of the book's language.
+func frontMatterLocale(fm *FrontMatter) Locale {
+	locale, _ := LookupLocale(EpubLanguage(fm))
+	return locale
+}
+
+// EpubIdentifier returns the book's unique identifier. When the front matter
+// does not supply one, a stable name-based UUID is derived from the title
+// and author so rebuilds of the same book keep the same identifier.
+func EpubIdentifier(fm *FrontMatter) string {
+	if fm.Identifier != "" {
+		return fm.Identifier
+	}
+	sum := sha1.Sum([]byte(fm.Title + "\x00" + fm.Author))
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// writeEpub writes the EPUB container with the given content documents to fd.
+func writeEpub(fd *os.File, fm *FrontMatter, items []epubItem) error {
+	lang := html.EscapeString(EpubLanguage(fm))
+	zw := zip.NewWriter(fd)
+	// The mimetype entry must come first and be stored uncompressed.
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte("application/epub+zip")); err != nil {
+		return err
+	}
+
+	parts := []zipPart{
+		{"META-INF/container.xml", epubContainerXML},
+		{"OEBPS/content.opf", epubPackage(fm, items)},
+		{"OEBPS/nav.xhtml", epubNav(fm, items)},
+		{"OEBPS/style.css", epubCSS},
+		{"OEBPS/text/title.xhtml", fmt.Sprintf(epubXHTML, lang, lang, html.EscapeString(fm.Title), "../style.css", "titlepage",
+			fmt.Sprintf("<h1 class=\"title\">%s</h1>\n<p class=\"author\">%s</p>\n", html.EscapeString(fm.Title), html.EscapeString(fm.Author)))},
+	}
+	for _, item := range items {
+		var body strings.Builder
+		if item.Heading != "" {
+			fmt.Fprintf(&body, "<h1>%s</h1>\n", html.EscapeString(item.Heading))
+		}
+		body.WriteString(item.Body)
+		parts = append(parts, zipPart{"OEBPS/" + item.Href, fmt.Sprintf(epubXHTML, lang, lang, html.EscapeString(item.Title), "../style.css", item.EpubType, body.String())})
+	}
+	if err := writeZipParts(zw, parts); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func epubPackage(fm *FrontMatter, items []epubItem) string {
+	var manifest, spine strings.Builder
+	for _, item := range items {
+		fmt.Fprintf(&manifest, "    <item id=\"%s\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", item.ID, item.Href)
+		fmt.Fprintf(&spine, "    <itemref idref=\"%s\"/>\n", item.ID)
+	}
+	modified := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	return fmt.Sprintf(epubPackageOPF,
+		html.EscapeString(EpubIdentifier(fm)),
+		html.EscapeString(fm.Title),
+		html.EscapeString(fm.Author),
+		html.EscapeString(EpubLanguage(fm)),
+		modified,
+		manifest.String(),
+		spine.String())
+}
+
+func epubNav(fm *FrontMatter, items []epubItem) string {
+	var toc tocBuilder
+	for _, item := range items {
+		switch {
+		case item.EpubType == "part":
+			toc.addPart(item.Href, item.Title)
+		case item.PartStart:
+			toc.endPart()
+		}
+		if item.Heading != "" {
+			toc.addChapter(item.Href, item.Heading)
+		}
+	}
+	lang := html.EscapeString(EpubLanguage(fm))
+	locale := frontMatterLocale(fm)
+	contents := html.EscapeString(locale.contents())
+	body := fmt.Sprintf(epubNavBody, contents, toc.String(), html.EscapeString(locale.titlePage()))
+	return fmt.Sprintf(epubXHTML, lang, lang, contents, "style.css", "toc", body)
+}
+
+const epubContainerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const epubPackageOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">%s</dc:identifier>
+    <dc:title>%s</dc:title>
+    <dc:creator>%s</dc:creator>
+    <dc:language>%s</dc:language>
+    <meta property="dcterms:modified">%s</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="css" href="style.css" media-type="text/css"/>
+    <item id="title" href="text/title.xhtml" media-type="application/xhtml+xml"/>
+%s  </manifest>
+  <spine>
+    <itemref idref="title"/>
+%s  </spine>
+</package>
+`
+
+const epubNavBody = `<nav epub:type="toc" id="toc">
+<h1>%s</h1>
+<ol>
+%s</ol>
+</nav>
+<nav epub:type="landmarks" hidden="">
+<ol>
+<li><a epub:type="titlepage" href="text/title.xhtml">%s</a></li>
+</ol>
+</nav>
+`
+
+const epubXHTML = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="%s" xml:lang="%s">
+<head>
+<title>%s</title>
+<link rel="stylesheet" type="text/css" href="%s"/>
+</head>
+<body>
+<section epub:type="%[5]s" class="%[5]s">
+%s</section>
+</body>
+</html>
+`
+
+const epubCSS = `body { font-family: serif; line-height: 1.4; }
+h1 { text-align: center; margin: 3em 0 2em; font-weight: normal; }
+h1.title { margin-top: 30%; }
+p { margin: 0; text-indent: 1.5em; }
+h1 + p, h2 + p, hr + p, .scene-break + p, blockquote + p { text-indent: 0; }
+p.author { text-align: center; text-indent: 0; }
+section.dedication, section.epigraph { text-align: center; font-style: italic; margin-top: 30%; }
+section.dedication p, section.epigraph p, section.copyright-page p { text-indent: 0; }
+section.copyright-page { font-size: 0.85em; }
+section.part { text-align: center; margin-top: 30%; }
+section.part p { text-indent: 0; font-style: italic; }
+blockquote { margin: 1em 2em; }
+hr.scene-break { border: none; margin: 1em 0; text-align: center; }
+hr.scene-break::after { content: "* * *"; }
+p.scene-break { margin: 1em 0; text-align: center; text-indent: 0; }
+p.scene-break img { height: 1.5em; }
+nav ol { list-style: none; padding: 0; }
+ins { color: #1a7f37; }
+del { color: #b31d28; }
+mark { background: #fff3a3; }
+.critic-comment { font-size: 0.85em; color: #555; }
+.critic-comment::before { content: " ["; }
+.critic-comment::after { content: "]"; }
+a[role="doc-noteref"], a[role="doc-backlink"] { text-decoration: none; }
+div.notes { margin-top: 2em; font-size: 0.9em; }
+ol.notes p { text-indent: 0; margin-bottom: 0.5em; }
+`
@@ -0,0 +1,134 @@
+package binder
+
+import (
+	"archive/zip"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssembleEpub_ValidBook(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.epub")
+
+	fm, err := AssembleEpub(EpubConfig{
+		InputFile:  "testdata/valid_book.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, fm)
+
+	zr, err := zip.OpenReader(outFile)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	// mimetype must be the first, uncompressed entry
+	require.NotEmpty(t, zr.File)
+	assert.Equal(t, "mimetype", zr.File[0].Name)
+	assert.Equal(t, zip.Store, zr.File[0].Method)
+	assert.Equal(t, "application/epub+zip", readZipEntry(t, outFile, "mimetype"))
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.Contains(t, names, "OEBPS/text/001-interlude.xhtml")
+	assert.Contains(t, names, "OEBPS/text/002-chapter-one.xhtml")
+	assert.Contains(t, names, "OEBPS/text/003-interlude.xhtml")
+	assert.Contains(t, names, "OEBPS/text/004-chapter-two.xhtml")
+
+	opf := readZipEntry(t, outFile, "OEBPS/content.opf")
+	assert.Contains(t, opf, "<dc:title>Test Book</dc:title>")
+	assert.Contains(t, opf, "<dc:creator>Test Author</dc:creator>")
+	assert.Contains(t, opf, "<dc:language>en</dc:language>")
+	assert.Contains(t, opf, "urn:uuid:")
+	assert.Regexp(t, `(?s)idref="c001".*idref="c002".*idref="c003".*idref="c004"`, opf)
+
+	nav := readZipEntry(t, outFile, "OEBPS/nav.xhtml")
+	assert.Contains(t, nav, `<a href="text/002-chapter-one.xhtml">Chapter One</a>`)
+	assert.Contains(t, nav, `<a href="text/004-chapter-two.xhtml">Chapter Two</a>`)
+	assert.NotContains(t, nav, "001-interlude", "unnamed interludes stay out of the table of contents")
+
+	chapter := readZipEntry(t, outFile, "OEBPS/text/002-chapter-one.xhtml")
+	assert.Contains(t, chapter, "<h1>Chapter One</h1>")
+	assert.Contains(t, chapter, "<p>This is foo.</p>")
+	assert.Contains(t, chapter, `<hr class="scene-break"/>`)
+	assert.Contains(t, chapter, `epub:type="chapter"`)
+
+	interlude := readZipEntry(t, outFile, "OEBPS/text/001-interlude.xhtml")
+	assert.NotContains(t, interlude, "<h1>")
+	assert.Contains(t, interlude, `epub:type="division"`)
+}
+
+func TestAssembleEpub_MissingInput(t *testing.T) {
+	fm, err := AssembleEpub(EpubConfig{
+		InputFile:  "testdata/nonexistent.yaml",
+		OutputFile: filepath.Join(t.TempDir(), "book.epub"),
+	})
+	require.Error(t, err)
+	assert.Nil(t, fm)
+}
+
+func TestEpubLanguage(t *testing.T) {
+	assert.Equal(t, "en", EpubLanguage(&FrontMatter{}))
+	assert.Equal(t, "es", EpubLanguage(&FrontMatter{Language: "es"}))
+}
+
+func TestEpubIdentifier(t *testing.T) {
+	assert.Equal(t, "isbn:9780000000000", EpubIdentifier(&FrontMatter{Identifier: "isbn:9780000000000"}))
+
+	fm := &FrontMatter{Title: "Test Book", Author: "Test Author"}
+	id := EpubIdentifier(fm)
+	assert.Regexp(t, `^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+	assert.Equal(t, id, EpubIdentifier(fm), "derived identifier should be stable")
+	assert.NotEqual(t, id, EpubIdentifier(&FrontMatter{Title: "Other Book", Author: "Test Author"}))
+}
+
+func TestAssembleEpub_WithParts(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.epub")
+
+	_, err := AssembleEpub(EpubConfig{
+		InputFile:  "testdata/book_with_parts.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+
+	part := readZipEntry(t, outFile, "OEBPS/text/002-part-one-the-fall.xhtml")
+	assert.Contains(t, part, `epub:type="part"`)
+	assert.Contains(t, part, "<h1>Part One: The Fall</h1>")
+
+	nav := readZipEntry(t, outFile, "OEBPS/nav.xhtml")
+	assert.Contains(t, nav, "Part One: The Fall</a>\n<ol>\n<li><a href=\"text/003-chapter-one.xhtml\">Chapter One</a></li>\n</ol></li>")
+	assert.Contains(t, nav, "</ol></li>\n<li><a href=\"text/007-epilogue.xhtml\">Epilogue</a></li>")
+}
+
+func TestAssembleEpub_BackMatterAfterPart(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.epub")
+	_, err := AssembleEpub(EpubConfig{InputFile: "testdata/book_with_parts_and_matter.yaml", OutputFile: outFile})
+	require.NoError(t, err)
+
+	nav := readZipEntry(t, outFile, "OEBPS/nav.xhtml")
+	assert.Contains(t, nav, "Chapter One</a></li>\n</ol></li>\n<li><a href=\"text/003-acknowledgments.xhtml\">Acknowledgments</a></li>")
+}
+
+func TestAssembleEpub_WithMatterSections(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.epub")
+
+	_, err := AssembleEpub(EpubConfig{
+		InputFile:  "testdata/book_with_matter.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+
+	dedication := readZipEntry(t, outFile, "OEBPS/text/001-dedication.xhtml")
+	assert.Contains(t, dedication, `epub:type="dedication"`)
+	assert.Contains(t, dedication, "<title>Dedication</title>")
+
+	copyright := readZipEntry(t, outFile, "OEBPS/text/002-copyright.xhtml")
+	assert.Contains(t, copyright, `epub:type="copyright-page"`)
+
+	about := readZipEntry(t, outFile, "OEBPS/text/006-about-test-author.xhtml")
+	assert.Contains(t, about, `epub:type="backmatter"`)
+	assert.Contains(t, about, "<h1>About Test Author</h1>")
+}
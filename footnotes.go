@@ -0,0 +1,304 @@
+package binder
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Footnote placements, choosing where the notes of a book are set.
+const (
+	// FootnotesInline sets each note at the foot of the page it is
+	// referenced on, or after its chapter in HTML and EPUB, which have no
+	// pages.
+	FootnotesInline = "inline"
+	// FootnotesChapter collects the notes of each chapter under a Notes
+	// heading at its end.
+	FootnotesChapter = "chapter"
+	// FootnotesBook collects every note in a Notes section at the end of
+	// the book, grouped by chapter and numbered through the book.
+	FootnotesBook = "book"
+)
+
+// ValidateFootnoteMode checks that mode is a known footnote placement. The
+// empty mode is FootnotesInline.
+func ValidateFootnoteMode(mode string) error {
+	switch mode {
+	case "", FootnotesInline, FootnotesChapter, FootnotesBook:
+		return nil
+	default:
+		return fmt.Errorf("unknown footnote placement %q (want inline, chapter or book)", mode)
+	}
+}
+
+// footnote is a markdown footnote taken out of a scene: a reference such
+// as [^1] and its definition, a line starting "[^1]:" with any lines that
+// continue it.
+type footnote struct {
+	id     string // unique across the book
+	number int    // the number shown at the reference
+	text   string // the definition, as markdown
+	// chapter is the position in the book of the chapter the reference is
+	// in, and label its label.
+	chapter int
+	label   string
+	// page is where the reference is, for links back from notes collected
+	// on another page; empty when they share a page.
+	page string
+}
+
+var (
+	footnoteRefPattern = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+	footnoteDefPattern = regexp.MustCompile(`^ {0,3}\[\^([^\]\s]+)\]:[ \t]*(.*)$`)
+)
+
+// footnoter numbers the footnotes of a book's scenes in reading order.
+// Scene labels only have to be unique within their scene; each note is
+// given an ID unique across the book, the chapter's position and the
+// note's within it, or the note's number when notes are collected for the
+// whole book. Numbers restart with each chapter unless they are.
+type footnoter struct {
+	mode    string
+	heading string // heading of collected notes
+	chapter int    // position of the current chapter in the book
+	label   string
+	count   int // notes in the current chapter
+	number  int // number of the last note
+}
+
+// newFootnoter returns a footnoter for the book's notes.
+func newFootnoter(book *Book) *footnoter {
+	return &footnoter{mode: book.Footnotes, heading: book.notesHeading()}
+}
+
+// startChapter moves on to the next chapter, labelled label.
+func (f *footnoter) startChapter(label string) {
+	f.chapter++
+	f.label = label
+	f.count = 0
+	if f.mode != FootnotesBook {
+		f.number = 0
+	}
+}
+
+// endnotes reports whether notes are collected rather than set at their
+// references.
+func (f *footnoter) endnotes() bool {
+	return f.mode == FootnotesChapter || f.mode == FootnotesBook
+}
+
+// scene takes the footnote definitions out of a scene's text and rewrites
+// its references as [^ID], returning the text and its notes in reference
+// order. A label referenced more than once gives a note at each reference,
+// as a footnote cannot be shared in print. Notes that are never referenced
+// are dropped, and references without a definition are escaped so that
+// they read as written.
+func (f *footnoter) scene(text string) (string, []footnote) {
+	body, definitions := extractFootnotes(text)
+	if len(definitions) == 0 && !strings.Contains(body, "[^") {
+		return text, nil
+	}
+	var notes []footnote
+	body = replaceFootnoteRefs(body, func(label string) string {
+		definition, ok := definitions[label]
+		if !ok {
+			return escapeFootnoteRef(label)
+		}
+		f.count++
+		f.number++
+		note := footnote{
+			id:      fmt.Sprintf("%d-%d", f.chapter, f.count),
+			number:  f.number,
+			text:    replaceFootnoteRefs(definition, escapeFootnoteRef),
+			chapter: f.chapter,
+			label:   f.label,
+		}
+		if f.mode == FootnotesBook {
+			note.id = strconv.Itoa(f.number)
+		}
+		notes = append(notes, note)
+		return "[^" + note.id + "]"
+	})
+	return body, notes
+}
+
+// escapeFootnoteRef writes a reference so that it reads as typed rather
+// than being taken for a note.
+func escapeFootnoteRef(label string) string {
+	return `\[^` + label + "]"
+}
+
+// replaceFootnoteRefs calls replace with the label of each footnote
+// reference in text that is not backslash-escaped, substituting what it
+// returns.
+func replaceFootnoteRefs(text string, replace func(label string) string) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range footnoteRefPattern.FindAllStringSubmatchIndex(text, -1) {
+		if m[0] > 0 && text[m[0]-1] == '\\' {
+			continue
+		}
+		sb.WriteString(text[last:m[0]])
+		sb.WriteString(replace(text[m[2]:m[3]]))
+		last = m[1]
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// extractFootnotes removes the footnote definitions from text, returning
+// the rest of the text and the definitions by label. A definition runs on
+// until a blank line, and on through further paragraphs that are indented.
+// Where labels are repeated the first definition wins.
+func extractFootnotes(text string) (string, map[string]string) {
+	lines := strings.Split(text, "\n")
+	definitions := map[string]string{}
+	var kept []string
+	for i := 0; i < len(lines); i++ {
+		m := footnoteDefPattern.FindStringSubmatch(lines[i])
+		if m == nil {
+			kept = append(kept, lines[i])
+			continue
+		}
+		paragraphs := []string{strings.TrimSpace(m[2])}
+		for i+1 < len(lines) {
+			next := lines[i+1]
+			if strings.TrimSpace(next) == "" {
+				// A blank line ends the note unless an indented paragraph
+				// follows it.
+				j := i + 1
+				for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+					j++
+				}
+				if j == len(lines) || !isIndented(lines[j]) {
+					break
+				}
+				paragraphs = append(paragraphs, "")
+				i = j - 1
+				continue
+			}
+			if footnoteDefPattern.MatchString(next) {
+				break
+			}
+			if last := len(paragraphs) - 1; paragraphs[last] == "" {
+				paragraphs[last] = strings.TrimSpace(next)
+			} else {
+				paragraphs[last] += "\n" + strings.TrimSpace(next)
+			}
+			i++
+		}
+		if _, ok := definitions[m[1]]; !ok {
+			definitions[m[1]] = strings.Join(paragraphs, "\n\n")
+		}
+		// Drop the blank lines after the definition when the kept text
+		// already ends with one, so no run of blank lines is left.
+		for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) == "" && (len(kept) == 0 || strings.TrimSpace(kept[len(kept)-1]) == "") {
+			i++
+		}
+	}
+	if len(definitions) == 0 {
+		return text, definitions
+	}
+	body := strings.TrimRight(strings.Join(kept, "\n"), "\n")
+	if strings.HasSuffix(text, "\n") {
+		body += "\n"
+	}
+	return body, definitions
+}
+
+func isIndented(line string) bool {
+	return strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ")
+}
+
+// FootnoteProblems describes the footnotes in a scene's text that would
+// not come out as written: references without a definition, definitions
+// that are never referenced and labels that are defined more than once.
+func FootnoteProblems(text string) []string {
+	var problems []string
+	defined := map[string]int{}
+	var order []string
+	for line := range strings.Lines(text) {
+		if m := footnoteDefPattern.FindStringSubmatch(strings.TrimRight(line, "\r\n")); m != nil {
+			if defined[m[1]] == 0 {
+				order = append(order, m[1])
+			}
+			defined[m[1]]++
+		}
+	}
+	body, _ := extractFootnotes(text)
+	referenced := map[string]bool{}
+	replaceFootnoteRefs(body, func(label string) string {
+		if defined[label] == 0 && !referenced[label] {
+			problems = append(problems, fmt.Sprintf("footnote [^%s] has no definition", label))
+		}
+		referenced[label] = true
+		return ""
+	})
+	for _, label := range order {
+		if defined[label] > 1 {
+			problems = append(problems, fmt.Sprintf("footnote [^%s] is defined %d times", label, defined[label]))
+		}
+		if !referenced[label] {
+			problems = append(problems, fmt.Sprintf("footnote [^%s] is never referenced", label))
+		}
+	}
+	return problems
+}
+
+// footnoteNumber returns the number shown for a note with the given ID.
+func footnoteNumber(id string) string {
+	if i := strings.LastIndexByte(id, '-'); i >= 0 {
+		return id[i+1:]
+	}
+	return id
+}
+
+// isFootnoteID reports whether id is one footnoter gives notes.
+func isFootnoteID(id string) bool {
+	chapter, count, found := strings.Cut(id, "-")
+	return isDigits(chapter) && (!found || isDigits(count))
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// notesHeading returns the heading for the book's collected notes in its
+// language.
+func (b *Book) notesHeading() string {
+	locale, _ := LookupLocale(b.Language)
+	if locale.NotesLabel != "" {
+		return locale.NotesLabel
+	}
+	return "Notes"
+}
+
+// footnotesByID indexes notes by their IDs.
+func footnotesByID(notes []footnote) map[string]footnote {
+	byID := make(map[string]footnote, len(notes))
+	for _, note := range notes {
+		byID[note.id] = note
+	}
+	return byID
+}
+
+// groupByChapter splits notes into runs that belong to the same chapter.
+func groupByChapter(notes []footnote) [][]footnote {
+	var groups [][]footnote
+	for i, note := range notes {
+		if i == 0 || note.chapter != notes[i-1].chapter {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], note)
+	}
+	return groups
+}
@@ -0,0 +1,220 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// footnoteBook writes a copy of testdata/book_with_footnotes.yaml that
+// places notes as mode asks, returning its path.
+func footnoteBook(t *testing.T, mode string) string {
+	t.Helper()
+	data, err := os.ReadFile("testdata/book_with_footnotes.yaml")
+	require.NoError(t, err)
+	base, err := filepath.Abs("testdata/scenes")
+	require.NoError(t, err)
+	spec := strings.Replace(string(data), `base_dir: "scenes"`, "base_dir: \""+base+"\"\n  footnotes: "+mode, 1)
+	path := filepath.Join(t.TempDir(), "book.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(spec), 0644))
+	return path
+}
+
+func TestFootnoter_Scene(t *testing.T) {
+	notes := &footnoter{}
+	notes.startChapter("One")
+	text, found := notes.scene("A[^1] and B[^b] and C[^none].\n\n[^1]: First.\n[^b]: Second,\ncontinued.\n")
+	assert.Equal(t, "A[^1-1] and B[^1-2] and C\\[^none].\n", text)
+	assert.Equal(t, []footnote{
+		{id: "1-1", number: 1, text: "First.", chapter: 1, label: "One"},
+		{id: "1-2", number: 2, text: "Second,\ncontinued.", chapter: 1, label: "One"},
+	}, found)
+
+	// Labels belong to their scene, so a second [^1] is a new note.
+	text, found = notes.scene("D[^1].\n\n[^1]: Third.")
+	assert.Equal(t, "D[^1-3].", text)
+	require.Len(t, found, 1)
+	assert.Equal(t, 3, found[0].number)
+
+	notes.startChapter("Two")
+	_, found = notes.scene("E[^1].\n\n[^1]: Fourth.")
+	assert.Equal(t, footnote{id: "2-1", number: 1, text: "Fourth.", chapter: 2, label: "Two"}, found[0])
+}
+
+func TestFootnoter_BookNumbering(t *testing.T) {
+	notes := &footnoter{mode: FootnotesBook}
+	notes.startChapter("One")
+	notes.scene("A[^1].\n\n[^1]: First.")
+	notes.startChapter("Two")
+	text, found := notes.scene("B[^1].\n\n[^1]: Second.")
+	assert.Equal(t, "B[^2].", text)
+	assert.Equal(t, 2, found[0].number)
+}
+
+func TestFootnoter_RepeatedReference(t *testing.T) {
+	notes := &footnoter{}
+	notes.startChapter("One")
+	text, found := notes.scene("A[^1] and again[^1].\n\n[^1]: Shared.")
+	assert.Equal(t, "A[^1-1] and again[^1-2].", text)
+	assert.Len(t, found, 2)
+}
+
+func TestExtractFootnotes(t *testing.T) {
+	data, err := os.ReadFile("testdata/scenes/more_footnotes.md")
+	require.NoError(t, err)
+	body, definitions := extractFootnotes(string(data))
+	assert.Equal(t, "A second account[^1] differs.\n\nThe letters end there.\n", body)
+	assert.Equal(t, map[string]string{"1": "Letters, 1921.\n\nKept in the archive."}, definitions)
+
+	body, definitions = extractFootnotes("No notes.\n")
+	assert.Equal(t, "No notes.\n", body)
+	assert.Empty(t, definitions)
+}
+
+func TestFootnoteProblems(t *testing.T) {
+	data, err := os.ReadFile("testdata/scenes/footnotes.md")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"footnote [^missing] has no definition"}, FootnoteProblems(string(data)))
+
+	assert.Equal(t, []string{
+		"footnote [^a] is defined 2 times",
+		"footnote [^b] is never referenced",
+	}, FootnoteProblems("See[^a].\n\n[^a]: One.\n[^a]: Two.\n[^b]: Unused.\n"))
+	assert.Empty(t, FootnoteProblems(`An escaped \[^x] reference.`))
+}
+
+func TestParseInline_FootnoteReference(t *testing.T) {
+	assert.Equal(t, []Span{{Text: "Word"}, {Text: "2", Note: "3-2"}, {Text: "."}}, parseInline("Word[^3-2]."))
+	assert.Equal(t, []Span{{Text: "[^label]"}}, parseInline("[^label]"))
+}
+
+func TestValidateFootnoteMode(t *testing.T) {
+	for _, mode := range []string{"", FootnotesInline, FootnotesChapter, FootnotesBook} {
+		assert.NoError(t, ValidateFootnoteMode(mode))
+	}
+	assert.ErrorContains(t, ValidateFootnoteMode("margin"), "unknown footnote placement")
+}
+
+func TestWriteMarkdownScenes_Footnotes(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "chapter.md")
+	fd, err := os.Create(outFile)
+	require.NoError(t, err)
+	err = WriteMarkdownScenesOptions(fd, []string{"testdata/scenes/footnotes.md", "testdata/scenes/more_footnotes.md"}, SceneOptions{Chapter: 2})
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	text := string(content)
+	assert.Contains(t, text, "The river rose[^2-1] and the town *held*[^2-2].")
+	assert.Contains(t, text, `again\[^missing].`)
+	assert.Contains(t, text, "A second account[^2-3] differs.")
+	assert.True(t, strings.HasSuffix(text, "The letters end there.\n\n\n[^2-1]: Records from the county office.\n\n"+
+		"[^2-2]: Or so the mayor claimed.\n    He was not there.\n\n"+
+		"[^2-3]: Letters, 1921.\n\n    Kept in the archive.\n"), text)
+}
+
+func TestAssembleHTML_Footnotes(t *testing.T) {
+	render := func(mode string) string {
+		outFile := filepath.Join(t.TempDir(), "book.html")
+		_, err := AssembleHTML(HTMLConfig{InputFile: footnoteBook(t, mode), OutputFile: outFile})
+		require.NoError(t, err)
+		content, err := os.ReadFile(outFile)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	page := render(FootnotesInline)
+	assert.Contains(t, page, `rose<a href="#fn-1-1" id="fnref-1-1" role="doc-noteref"><sup>1</sup></a>`)
+	assert.Contains(t, page, `<li id="fn-1-1" value="1"><p>Records from the county office. <a href="#fnref-1-1" role="doc-backlink">↩</a></p>`)
+	assert.Contains(t, page, `<li id="fn-2-1" value="1">`, "numbers restart with each chapter")
+	assert.NotContains(t, page, "<h2>Notes</h2>")
+
+	page = render(FootnotesChapter)
+	assert.Equal(t, 2, strings.Count(page, "<h2>Notes</h2>"))
+
+	page = render(FootnotesBook)
+	assert.Contains(t, page, `<section class="notes" id="notes">`)
+	assert.Contains(t, page, `<a href="#notes">Notes</a>`)
+	assert.Contains(t, page, "<h2>The Letters</h2>\n<ol class=\"notes\">\n<li id=\"fn-4\" value=\"4\">")
+	assert.NotContains(t, page, `<div class="notes"`)
+}
+
+func TestAssembleEpub_BookNotes(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.epub")
+	_, err := AssembleEpub(EpubConfig{InputFile: footnoteBook(t, FootnotesBook), OutputFile: outFile})
+	require.NoError(t, err)
+
+	chapter := readZipEntry(t, outFile, "OEBPS/text/001-the-flood.xhtml")
+	assert.Contains(t, chapter, `<a href="notes.xhtml#fn-1" id="fnref-1" role="doc-noteref">`)
+	notes := readZipEntry(t, outFile, "OEBPS/text/notes.xhtml")
+	assert.Contains(t, notes, `epub:type="endnotes"`)
+	assert.Contains(t, notes, `<a href="001-the-flood.xhtml#fnref-1" role="doc-backlink">`)
+	assert.Contains(t, readZipEntry(t, outFile, "OEBPS/content.opf"), `href="text/notes.xhtml"`)
+}
+
+func TestAssembleLatex_Footnotes(t *testing.T) {
+	render := func(mode string) string {
+		outFile := filepath.Join(t.TempDir(), "book.tex")
+		_, err := AssembleLatex(LatexConfig{InputFile: footnoteBook(t, mode), OutputFile: outFile})
+		require.NoError(t, err)
+		content, err := os.ReadFile(outFile)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	tex := render(FootnotesInline)
+	assert.Contains(t, tex, `rose\footnote{Records from the county office.} and`)
+	assert.Contains(t, tex, `account\footnote{Letters, 1921.`+"\n\n"+`Kept in the archive.} differs.`)
+
+	tex = render(FootnotesChapter)
+	assert.Contains(t, tex, `rose\textsuperscript{1} and`)
+	assert.Contains(t, tex, "\\section*{Notes}\n\n\\begin{enumerate}\n\\item[1.] Records from the county office.\n")
+
+	tex = render(FootnotesBook)
+	assert.Contains(t, tex, `One more\textsuperscript{4}.`)
+	assert.Contains(t, tex, "\\backmatter\n\n\\chapter*{Notes}\n\\addcontentsline{toc}{chapter}{Notes}\n\n\\section*{The Flood}\n\n")
+	assert.Contains(t, tex, `\item[4.] The last note.`)
+}
+
+func TestAssembleDocx_Footnotes(t *testing.T) {
+	render := func(mode string) string {
+		outFile := filepath.Join(t.TempDir(), "book.docx")
+		_, err := AssembleDocx(DocxConfig{InputFile: footnoteBook(t, mode), OutputFile: outFile})
+		require.NoError(t, err)
+		return outFile
+	}
+
+	outFile := render(FootnotesInline)
+	document := readZipEntry(t, outFile, "word/document.xml")
+	assert.Contains(t, document, `<w:footnoteReference w:id="1"/>`)
+	assert.Contains(t, document, `<w:footnoteReference w:id="4"/>`)
+	footnotes := readZipEntry(t, outFile, "word/footnotes.xml")
+	assert.Contains(t, footnotes, `<w:footnote w:id="1"><w:p><w:pPr><w:pStyle w:val="FootnoteText"/></w:pPr>`)
+	assert.Contains(t, footnotes, "Records from the county office.")
+	assert.Contains(t, readZipEntry(t, outFile, "word/_rels/document.xml.rels"), `Target="footnotes.xml"`)
+
+	outFile = render(FootnotesChapter)
+	document = readZipEntry(t, outFile, "word/document.xml")
+	assert.NotContains(t, document, "w:footnoteReference")
+	assert.Contains(t, document, `<w:vertAlign w:val="superscript"/></w:rPr><w:t xml:space="preserve">1</w:t>`)
+	assert.Contains(t, document, `<w:pStyle w:val="Note"/></w:pPr><w:r><w:t xml:space="preserve">1. </w:t></w:r>`)
+}
+
+func TestLintBook_Footnotes(t *testing.T) {
+	issues, err := LintBook("testdata/book_with_footnotes.yaml")
+	require.NoError(t, err)
+	var messages []string
+	for _, issue := range issues {
+		if strings.Contains(issue.Message, "footnote") {
+			messages = append(messages, issue.String())
+		}
+	}
+	assert.Equal(t, []string{
+		"testdata/book_with_footnotes.yaml:10: scene " + filepath.Join("testdata", "scenes", "footnotes.md") + ": footnote [^missing] has no definition",
+	}, messages)
+}
@@ -0,0 +1,287 @@
+package binder
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// HTMLConfig holds the parameters for assembling a book as a single
+// self-contained HTML file.
+type HTMLConfig struct {
+	InputFile  string
+	OutputFile string
+	Drafts     bool   // keep author notes in the output
+	Critic     string // CriticAccept (default), CriticReject or CriticReview
+}
+
+// AssembleHTML renders a book into one HTML file with embedded CSS, a title
+// page built from the front matter and a linked table of contents. Returns
+// the parsed FrontMatter.
+func AssembleHTML(config HTMLConfig) (*FrontMatter, error) {
+	if err := ValidateCriticMode(config.Critic); err != nil {
+		return nil, err
+	}
+	frontMatter, book, err := LoadBook(config.InputFile)
+	if err != nil {
+		return nil, err
+	}
+	options := book.sceneOptions()
+	options.Drafts = config.Drafts
+	options.Critic = config.Critic
+	page, err := renderBookHTML(frontMatter, book, options)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBytesAtomic(config.OutputFile, []byte(page)); err != nil {
+		return nil, err
+	}
+	return frontMatter, nil
+}
+
+// renderBookHTML renders the whole book as a single HTML document.
+func renderBookHTML(fm *FrontMatter, book *Book, options SceneOptions) (string, error) {
+	sections, err := renderHTMLSections(book, options, "")
+	if err != nil {
+		return "", err
+	}
+	var toc tocBuilder
+	var chapters strings.Builder
+	for _, section := range sections {
+		section.addToTOC(&toc, "#"+section.ID)
+		fmt.Fprintf(&chapters, "<section class=\"%s\" id=\"%s\">\n", section.Class, section.ID)
+		chapters.WriteString(section.Body)
+		chapters.WriteString("</section>\n")
+	}
+	return fmt.Sprintf(htmlDocument,
+		html.EscapeString(EpubLanguage(fm)),
+		html.EscapeString(fm.Title),
+		htmlCSS,
+		htmlTitlePage(fm),
+		html.EscapeString(frontMatterLocale(fm).contents()),
+		toc.String(),
+		chapters.String()), nil
+}
+
+// htmlSection is a part title or chapter rendered as HTML.
+type htmlSection struct {
+	ID      string // unique within the book, e.g. "002-chapter-one"
+	Class   string // part, chapter, interlude or "matter <section type>"
+	Heading string // empty for untitled sections and interludes
+	Body    string // rendered content, including the heading
+	// LeavesPart is set on the first chapter of an unnamed part and on
+	// sections outside any part, such as back matter, which close the
+	// preceding named part in the table of contents.
+	LeavesPart bool
+}
+
+// addToTOC adds the section to a table of contents, linking it to href.
+func (s htmlSection) addToTOC(toc *tocBuilder, href string) {
+	switch {
+	case s.Class == "part":
+		toc.addPart(href, s.Heading)
+		return
+	case s.LeavesPart:
+		toc.endPart()
+	}
+	if s.Heading != "" {
+		toc.addChapter(href, s.Heading)
+	}
+}
+
+// renderHTMLSections renders each part title and chapter of the book.
+// Notes collected for the whole book get a section of their own at the
+// end. When each section is served as a page of its own,
+// pagePrefix turns a section ID into its path so that notes and references
+// can link to each other; it is empty for a single page.
+func renderHTMLSections(book *Book, options SceneOptions, pagePrefix string) ([]htmlSection, error) {
+	var sections []htmlSection
+	notes := newFootnoter(book)
+	var bookNotes []footnote
+	page := func(id string) string {
+		if pagePrefix == "" {
+			return ""
+		}
+		return pagePrefix + id
+	}
+	cnum := 1
+	for chapter := range book.GetChapters() {
+		if err := chapter.Validate(); err != nil {
+			return nil, err
+		}
+		body, chapterNotes, err := renderChapterHTML(chapter, options, notes)
+		if err != nil {
+			return nil, err
+		}
+		if chapter.StartsNamedPart() {
+			sections = append(sections, htmlSection{
+				ID:      fmt.Sprintf("%03d-%s", cnum, chapter.Part.HeadingToFilename()),
+				Class:   "part",
+				Heading: chapter.Part.Heading,
+				Body:    renderPartHTML(chapter.Part),
+			})
+			cnum += 1
+		}
+		section := htmlSection{
+			ID:         fmt.Sprintf("%03d-%s", cnum, chapter.HeadingToFilename()),
+			Class:      "chapter",
+			Heading:    chapter.Heading,
+			LeavesPart: chapter.PartStart && !chapter.StartsNamedPart() || chapter.Part == nil,
+		}
+		cnum += 1
+		switch {
+		case chapter.Matter != BodyMatter:
+			section.Class = "matter " + slugify(chapter.SectionType)
+		case chapter.Interlude:
+			section.Class = "interlude"
+		}
+		if chapter.Heading != "" {
+			section.Body = fmt.Sprintf("<h1>%s</h1>\n", html.EscapeString(chapter.Heading))
+		}
+		if len(chapterNotes) > 0 && pagePrefix != "" {
+			body = linkNotes(body, page("notes"))
+		}
+		section.Body += body
+		sections = append(sections, section)
+		for _, note := range chapterNotes {
+			note.page = page(section.ID)
+			bookNotes = append(bookNotes, note)
+		}
+	}
+	if len(bookNotes) > 0 {
+		sections = append(sections, htmlSection{
+			ID:         "notes",
+			Class:      "notes",
+			Heading:    notes.heading,
+			Body:       fmt.Sprintf("<h1>%s</h1>\n", html.EscapeString(notes.heading)) + renderNotesHTML(bookNotes),
+			LeavesPart: true,
+		})
+	}
+	return sections, nil
+}
+
+// renderPartHTML renders a part heading and its optional text.
+func renderPartHTML(part *IteratedPart) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<h1>%s</h1>\n", html.EscapeString(part.Heading))
+	writeHTMLBlocks(&sb, ParseMarkdown(part.Text), htmlSceneBreak)
+	return sb.String()
+}
+
+// tocBuilder builds the list items of a table of contents, nesting chapter
+// entries beneath the part they belong to.
+type tocBuilder struct {
+	sb     strings.Builder
+	inPart bool
+	// partHasChapters tracks whether the open part's nested list has been
+	// started, since an empty <ol> is not valid in EPUB navigation documents.
+	partHasChapters bool
+}
+
+func (t *tocBuilder) addPart(href, label string) {
+	t.endPart()
+	fmt.Fprintf(&t.sb, "<li><a href=\"%s\">%s</a>", href, html.EscapeString(label))
+	t.inPart = true
+}
+
+func (t *tocBuilder) endPart() {
+	if !t.inPart {
+		return
+	}
+	if t.partHasChapters {
+		t.sb.WriteString("</ol>")
+	}
+	t.sb.WriteString("</li>\n")
+	t.inPart = false
+	t.partHasChapters = false
+}
+
+func (t *tocBuilder) addChapter(href, label string) {
+	if t.inPart && !t.partHasChapters {
+		t.sb.WriteString("\n<ol>\n")
+		t.partHasChapters = true
+	}
+	fmt.Fprintf(&t.sb, "<li><a href=\"%s\">%s</a></li>\n", href, html.EscapeString(label))
+}
+
+// String closes any open part and returns the accumulated list items.
+func (t *tocBuilder) String() string {
+	t.endPart()
+	return t.sb.String()
+}
+
+// htmlTitlePage renders the front matter as a title page.
+func htmlTitlePage(fm *FrontMatter) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<h1 class=\"title\">%s</h1>\n", html.EscapeString(fm.Title))
+	if fm.Author != "" {
+		fmt.Fprintf(&sb, "<p class=\"author\">%s</p>\n", html.EscapeString(fm.Author))
+	}
+	var contact []string
+	for _, line := range []string{fm.ContactName, fm.ContactAddress, fm.ContactCityStateZip, fm.ContactPhone, fm.ContactEmail} {
+		if line != "" {
+			contact = append(contact, html.EscapeString(line))
+		}
+	}
+	if len(contact) > 0 {
+		fmt.Fprintf(&sb, "<address>%s</address>\n", strings.Join(contact, "<br/>"))
+	}
+	return sb.String()
+}
+
+const htmlDocument = `<!DOCTYPE html>
+<html lang="%s">
+<head>
+<meta charset="utf-8"/>
+<meta name="viewport" content="width=device-width, initial-scale=1"/>
+<title>%s</title>
+<style>
+%s</style>
+</head>
+<body>
+<header class="title-page">
+%s</header>
+<nav class="toc">
+<h2>%s</h2>
+<ol>
+%s</ol>
+</nav>
+<main>
+%s</main>
+</body>
+</html>
+`
+
+const htmlCSS = `body { max-width: 36em; margin: 0 auto; padding: 2em 1em; font-family: Georgia, "Times New Roman", serif; font-size: 1.15em; line-height: 1.6; color: #222; background: #fdfcf8; }
+.title-page { min-height: 80vh; display: flex; flex-direction: column; justify-content: center; text-align: center; }
+.title-page .title { font-size: 2.5em; font-weight: normal; margin: 0 0 0.5em; }
+.title-page .author { font-size: 1.3em; text-indent: 0; }
+.title-page address { margin-top: 4em; font-style: normal; font-size: 0.85em; color: #666; }
+nav.toc { page-break-after: always; margin-bottom: 4em; }
+nav.toc ol { list-style: none; padding: 0; }
+nav.toc ol ol { padding-left: 1.5em; }
+nav.toc li { margin: 0.3em 0; }
+nav.toc a { color: inherit; text-decoration: none; border-bottom: 1px dotted #999; }
+section { margin-top: 5em; }
+section.part { min-height: 60vh; display: flex; flex-direction: column; justify-content: center; text-align: center; }
+section.part p { text-indent: 0; font-style: italic; }
+section.dedication, section.epigraph { text-align: center; font-style: italic; }
+section.matter p { text-indent: 0; margin-bottom: 0.8em; }
+h1 { text-align: center; font-weight: normal; margin-bottom: 2em; }
+p { margin: 0; text-indent: 1.5em; }
+h1 + p, h2 + p, hr + p, .scene-break + p, blockquote + p { text-indent: 0; }
+blockquote { margin: 1em 2em; font-style: italic; }
+hr.scene-break { border: none; margin: 1.5em 0; text-align: center; overflow: visible; height: auto; }
+hr.scene-break::after { content: "\2766"; font-size: 1.2em; color: #888; letter-spacing: 1em; }
+p.scene-break { margin: 1.5em 0; text-align: center; text-indent: 0; }
+p.scene-break img { height: 1.5em; }
+ins { color: #1a7f37; }
+del { color: #b31d28; }
+mark { background: #fff3a3; }
+.critic-comment { font-size: 0.85em; color: #555; background: #eef; padding: 0 0.3em; border-radius: 0.2em; }
+a[role="doc-noteref"], a[role="doc-backlink"] { text-decoration: none; }
+div.notes { margin-top: 3em; padding-top: 1em; border-top: 1px solid #ccc; font-size: 0.9em; }
+ol.notes { padding-left: 2em; }
+ol.notes p { text-indent: 0; margin-bottom: 0.5em; }
+@media print { section { page-break-before: always; } }
+`
@@ -0,0 +1,123 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssembleHTML_ValidBook(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.html")
+
+	fm, err := AssembleHTML(HTMLConfig{
+		InputFile:  "testdata/valid_book.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, fm)
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	page := string(content)
+
+	// Self-contained: embedded CSS, no external stylesheet
+	assert.Contains(t, page, "<style>")
+	assert.NotContains(t, page, `rel="stylesheet"`)
+
+	// Title page from front matter
+	assert.Contains(t, page, `<h1 class="title">Test Book</h1>`)
+	assert.Contains(t, page, `<p class="author">Test Author</p>`)
+	assert.Contains(t, page, "test@example.com")
+
+	// Table of contents links to chapter anchors
+	assert.Contains(t, page, `<a href="#002-chapter-one">Chapter One</a>`)
+	assert.Contains(t, page, `<section class="chapter" id="002-chapter-one">`)
+	assert.Contains(t, page, `<section class="interlude" id="001-interlude">`)
+
+	// Scene breaks are ornaments, not literal asterisks
+	assert.Contains(t, page, `<hr class="scene-break"/>`)
+	assert.NotContains(t, page, "***")
+
+	// Chapters appear in order
+	assert.Less(t, strings.Index(page, "This is interlude 1."), strings.Index(page, "This is foo."))
+	assert.Less(t, strings.Index(page, "This is foo."), strings.Index(page, "This is quux."))
+}
+
+func TestAssembleHTML_MissingInput(t *testing.T) {
+	fm, err := AssembleHTML(HTMLConfig{
+		InputFile:  "testdata/nonexistent.yaml",
+		OutputFile: filepath.Join(t.TempDir(), "book.html"),
+	})
+	require.Error(t, err)
+	assert.Nil(t, fm)
+}
+
+func TestHTMLTitlePage_OmitsEmptyContact(t *testing.T) {
+	page := htmlTitlePage(&FrontMatter{Title: "Solo"})
+
+	assert.Contains(t, page, "Solo")
+	assert.NotContains(t, page, "<address>")
+	assert.NotContains(t, page, "author")
+}
+
+func TestAssembleHTML_WithParts(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.html")
+
+	_, err := AssembleHTML(HTMLConfig{
+		InputFile:  "testdata/book_with_parts.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	page := string(content)
+
+	assert.Contains(t, page, `<section class="part" id="002-part-one-the-fall">`)
+	assert.Contains(t, page, "<p><em>Everything falls.</em></p>")
+	// Chapters nest beneath their part in the table of contents; the
+	// unnamed final part ends the nesting.
+	assert.Contains(t, page, "<li><a href=\"#002-part-one-the-fall\">Part One: The Fall</a>\n<ol>\n<li><a href=\"#003-chapter-one\">Chapter One</a></li>\n</ol></li>")
+	assert.Contains(t, page, "<li><a href=\"#005-part-two-the-rise\">Part Two: The Rise</a>\n<ol>\n<li><a href=\"#006-chapter-two\">Chapter Two</a></li>\n</ol></li>\n<li><a href=\"#007-epilogue\">Epilogue</a></li>")
+}
+
+func TestAssembleHTML_BackMatterAfterPart(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.html")
+	_, err := AssembleHTML(HTMLConfig{InputFile: "testdata/book_with_parts_and_matter.yaml", OutputFile: outFile})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "<li><a href=\"#001-part-one\">Part One</a>\n<ol>\n<li><a href=\"#002-chapter-one\">Chapter One</a></li>\n</ol></li>\n<li><a href=\"#003-acknowledgments\">Acknowledgments</a></li>")
+}
+
+func TestTocBuilder_PartWithoutNamedChapters(t *testing.T) {
+	var toc tocBuilder
+	toc.addPart("#p1", "Part One")
+	toc.addPart("#p2", "Part Two")
+	toc.addChapter("#c1", "Chapter One")
+
+	assert.Equal(t, "<li><a href=\"#p1\">Part One</a></li>\n<li><a href=\"#p2\">Part Two</a>\n<ol>\n<li><a href=\"#c1\">Chapter One</a></li>\n</ol></li>\n", toc.String())
+}
+
+func TestAssembleHTML_WithMatterSections(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.html")
+
+	_, err := AssembleHTML(HTMLConfig{
+		InputFile:  "testdata/book_with_matter.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	page := string(content)
+
+	assert.Contains(t, page, `<section class="matter dedication" id="001-dedication">`)
+	assert.Contains(t, page, `<a href="#005-acknowledgments">Acknowledgments</a>`)
+	assert.NotContains(t, page, `<a href="#001-dedication">`)
+}
@@ -0,0 +1,311 @@
+package binder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LatexSettings holds the print layout options for LaTeX output, read from
+// the "latex" key of the book spec.
+type LatexSettings struct {
+	DocumentClass string `yaml:"document_class,omitempty"` // "book" (default) or "memoir"
+	TrimSize      string `yaml:"trim_size,omitempty"`      // e.g. "6x9", "5.5in x 8.5in", "148mm x 210mm"
+	FontSize      string `yaml:"font_size,omitempty"`      // e.g. "11pt"
+	Font          string `yaml:"font,omitempty"`           // main font name; requires XeLaTeX or LuaLaTeX
+}
+
+// LatexConfig holds the parameters for assembling a book as a LaTeX document.
+type LatexConfig struct {
+	InputFile  string
+	OutputFile string
+	Drafts     bool   // keep author notes in the output
+	Critic     string // CriticAccept (default) or CriticReject; LaTeX has no review copy
+}
+
+var trimSizePattern = regexp.MustCompile(`^\s*([0-9]*\.?[0-9]+)\s*(in|mm|cm)?\s*[xX×]\s*([0-9]*\.?[0-9]+)\s*(in|mm|cm)?\s*$`)
+
+// ParseTrimSize parses a trim size such as "6x9" or "148mm x 210mm" into
+// LaTeX width and height dimensions. Sizes without a unit are in inches.
+func ParseTrimSize(size string) (string, string, error) {
+	m := trimSizePattern.FindStringSubmatch(size)
+	if m == nil {
+		return "", "", fmt.Errorf("invalid trim size %q (want WIDTHxHEIGHT, e.g. 6x9)", size)
+	}
+	widthUnit, heightUnit := m[2], m[4]
+	if widthUnit == "" {
+		widthUnit = heightUnit
+	}
+	if heightUnit == "" {
+		heightUnit = widthUnit
+	}
+	if widthUnit == "" {
+		widthUnit, heightUnit = "in", "in"
+	}
+	return m[1] + widthUnit, m[3] + heightUnit, nil
+}
+
+// AssembleLatex converts a book into a LaTeX document using the book or
+// memoir class. Chapters become \chapter, interludes \chapter*, parts a
+// \bookpart title page and scene breaks the \scenebreak macro. Front and
+// back matter sections are set unnumbered in \frontmatter and \backmatter.
+// Returns the parsed FrontMatter.
+func AssembleLatex(config LatexConfig) (*FrontMatter, error) {
+	if config.Critic == CriticReview {
+		return nil, fmt.Errorf("LaTeX output has no review copy (want accept or reject)")
+	}
+	if err := ValidateCriticMode(config.Critic); err != nil {
+		return nil, err
+	}
+	frontMatter, book, err := LoadBook(config.InputFile)
+	if err != nil {
+		return nil, err
+	}
+	preamble, err := latexPreamble(frontMatter, book.Latex)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	sb.WriteString(preamble)
+	// The contents list the parts and the matter sections added to them
+	// with \addcontentsline as well as the chapters.
+	sb.WriteString("\\begin{document}\n\n\\frontmatter\n\\maketitle\n\\tableofcontents\n\n")
+	division := FrontMatterSection
+	options := book.sceneOptions()
+	options.Drafts = config.Drafts
+	options.Critic = config.Critic
+	notes := newFootnoter(book)
+	var bookNotes []footnote
+	for chapter := range book.GetChapters() {
+		if err := chapter.Validate(); err != nil {
+			return nil, err
+		}
+		if chapter.Matter != division {
+			switch chapter.Matter {
+			case BodyMatter:
+				sb.WriteString("\\mainmatter\n\n")
+			case BackMatterSection:
+				sb.WriteString("\\backmatter\n\n")
+			}
+			division = chapter.Matter
+		}
+		if chapter.StartsNamedPart() {
+			var text strings.Builder
+			writeLatexBlocks(&text, ParseMarkdown(chapter.Part.Text), SceneBreak{}.latex(), nil)
+			fmt.Fprintf(&sb, "\\bookpart{%s}{%s}\n\n", latexEscape(chapter.Part.Heading), strings.TrimSpace(text.String()))
+		}
+		centered := false
+		switch {
+		case chapter.Matter != BodyMatter && chapter.Heading == "":
+			// Untitled sections such as dedications get a page of their own.
+			sb.WriteString("\\cleardoublepage\n\\thispagestyle{empty}\n\\vspace*{0.3\\textheight}\n\n")
+			centered = chapter.SectionType == "dedication" || chapter.SectionType == "epigraph"
+		case chapter.Matter != BodyMatter:
+			heading := latexEscape(chapter.Heading)
+			fmt.Fprintf(&sb, "\\chapter*{%s}\n\\addcontentsline{toc}{chapter}{%s}\n\n", heading, heading)
+		case chapter.Interlude:
+			fmt.Fprintf(&sb, "\\chapter*{%s}\n\n", latexEscape(chapter.Heading))
+		default:
+			fmt.Fprintf(&sb, "\\chapter{%s}\n\n", latexEscape(chapter.Heading))
+		}
+		if centered {
+			sb.WriteString("\\begin{center}\n\\itshape\n")
+		}
+		sceneBreak := chapter.SceneBreak.latex()
+		notes.startChapter(chapter.Label())
+		var chapterNotes []footnote
+		scenes, err := chapter.LoadScenes()
+		if err != nil {
+			return nil, err
+		}
+		for i := range scenes {
+			text := &scenes[i]
+			if i > 0 {
+				sb.WriteString(sceneBreak + "\n\n")
+			}
+			body, sceneNotes := notes.scene(options.body(text))
+			chapterNotes = append(chapterNotes, sceneNotes...)
+			var footnotes map[string]footnote
+			if !notes.endnotes() {
+				footnotes = footnotesByID(sceneNotes)
+			}
+			writeLatexBlocks(&sb, ParseMarkdown(body), sceneBreak, footnotes)
+		}
+		if centered {
+			sb.WriteString("\\end{center}\n\n")
+		}
+		switch {
+		case notes.mode == FootnotesChapter && len(chapterNotes) > 0:
+			fmt.Fprintf(&sb, "\\section*{%s}\n\n", latexEscape(notes.heading))
+			writeLatexNotes(&sb, chapterNotes)
+		case notes.mode == FootnotesBook:
+			bookNotes = append(bookNotes, chapterNotes...)
+		}
+	}
+	if len(bookNotes) > 0 {
+		if division != BackMatterSection {
+			sb.WriteString("\\backmatter\n\n")
+		}
+		writeLatexBookNotes(&sb, bookNotes, notes.heading)
+	}
+	sb.WriteString("\\end{document}\n")
+	if err := writeBytesAtomic(config.OutputFile, []byte(sb.String())); err != nil {
+		return nil, err
+	}
+	return frontMatter, nil
+}
+
+func latexPreamble(fm *FrontMatter, settings LatexSettings) (string, error) {
+	class := settings.DocumentClass
+	if class == "" {
+		class = "book"
+	}
+	if class != "book" && class != "memoir" {
+		return "", fmt.Errorf("unsupported LaTeX document class %q (want book or memoir)", class)
+	}
+	fontSize := settings.FontSize
+	if fontSize == "" {
+		fontSize = "11pt"
+	}
+	trim := settings.TrimSize
+	if trim == "" {
+		trim = "6x9"
+	}
+	width, height, err := ParseTrimSize(trim)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\\documentclass[%s,openany]{%s}\n", fontSize, class)
+	if class == "memoir" {
+		fmt.Fprintf(&sb, "\\setstocksize{%s}{%s}\n", height, width)
+		sb.WriteString("\\settrimmedsize{\\stockheight}{\\stockwidth}{*}\n")
+		sb.WriteString("\\setlrmarginsandblock{0.75in}{0.5in}{*}\n")
+		sb.WriteString("\\setulmarginsandblock{0.75in}{0.75in}{*}\n")
+		sb.WriteString("\\checkandfixthelayout\n")
+		// Chapter headings already carry their own wording, so suppress
+		// memoir's "Chapter N" label.
+		sb.WriteString("\\renewcommand{\\printchaptername}{}\n")
+		sb.WriteString("\\renewcommand{\\chapternamenum}{}\n")
+		sb.WriteString("\\renewcommand{\\printchapternum}{}\n")
+		sb.WriteString("\\renewcommand{\\afterchapternum}{}\n")
+	} else {
+		fmt.Fprintf(&sb, "\\usepackage[paperwidth=%s,paperheight=%s,inner=0.75in,outer=0.5in,top=0.75in,bottom=0.75in]{geometry}\n", width, height)
+		// Chapter headings already carry their own wording, so suppress
+		// the "Chapter N" label.
+		sb.WriteString("\\usepackage{titlesec}\n")
+		sb.WriteString("\\titleformat{\\chapter}[block]{\\normalfont\\Large\\centering}{}{0pt}{}\n")
+	}
+	if settings.Font != "" {
+		sb.WriteString("\\usepackage{fontspec}\n")
+		fmt.Fprintf(&sb, "\\setmainfont{%s}\n", latexEscape(settings.Font))
+	} else {
+		sb.WriteString("\\usepackage[T1]{fontenc}\n")
+		sb.WriteString("\\usepackage{lmodern}\n")
+	}
+	// Parts get a plain title page without the class's "Part I" label.
+	sb.WriteString("\\newcommand{\\bookpart}[2]{\\cleardoublepage\\thispagestyle{empty}\\vspace*{0.3\\textheight}\\begin{center}{\\Huge #1\\par}\\bigskip\\itshape #2\\end{center}\\addcontentsline{toc}{part}{#1}\\clearpage}\n")
+	// Ornaments that the default fonts lack are drawn from the dingbats
+	// font, or in the case of the asterism built from asterisks.
+	sb.WriteString("\\usepackage{pifont}\n")
+	sb.WriteString("\\newcommand{\\asterism}{\\smash{\\raisebox{-.5ex}{\\setlength{\\tabcolsep}{-.5pt}\\begin{tabular}{@{}cc@{}}\\multicolumn2c*\\\\[-2ex]*&*\\end{tabular}}}}\n")
+	// Scene breaks are three asterisks unless the book passes its own text
+	// or graphicx image as the optional argument.
+	sb.WriteString("\\usepackage{graphicx}\n")
+	sb.WriteString("\\newcommand{\\scenebreak}[1][*\\quad*\\quad*]{\\par\\bigskip\\begin{center}#1\\end{center}\\bigskip\\par\\noindent}\n")
+	fmt.Fprintf(&sb, "\\title{%s}\n", latexEscape(fm.Title))
+	fmt.Fprintf(&sb, "\\author{%s}\n", latexEscape(fm.Author))
+	sb.WriteString("\\date{}\n\n")
+	return sb.String(), nil
+}
+
+// writeLatexBlocks renders blocks as LaTeX, writing sceneBreak for scene
+// breaks. Note references are set as footnotes when their note is among
+// footnotes, and otherwise as superscript numbers.
+func writeLatexBlocks(sb *strings.Builder, blocks []Block, sceneBreak string, footnotes map[string]footnote) {
+	for _, block := range blocks {
+		switch block.Kind {
+		case HeadingBlock:
+			sb.WriteString("\\section*{")
+			writeLatexSpans(sb, block.Spans, footnotes)
+			sb.WriteString("}\n\n")
+		case QuoteBlock:
+			sb.WriteString("\\begin{quote}\n")
+			writeLatexSpans(sb, block.Spans, footnotes)
+			sb.WriteString("\n\\end{quote}\n\n")
+		case SceneBreakBlock:
+			sb.WriteString(sceneBreak + "\n\n")
+		default:
+			writeLatexSpans(sb, block.Spans, footnotes)
+			sb.WriteString("\n\n")
+		}
+	}
+}
+
+func writeLatexSpans(sb *strings.Builder, spans []Span, footnotes map[string]footnote) {
+	for _, span := range spans {
+		if span.Note != "" {
+			if note, ok := footnotes[span.Note]; ok {
+				var text strings.Builder
+				writeLatexBlocks(&text, ParseMarkdown(note.text), SceneBreak{}.latex(), nil)
+				fmt.Fprintf(sb, "\\footnote{%s}", strings.TrimSpace(text.String()))
+			} else {
+				fmt.Fprintf(sb, "\\textsuperscript{%s}", latexEscape(span.Text))
+			}
+			continue
+		}
+		text := latexEscape(span.Text)
+		if span.Italic {
+			text = "\\emph{" + text + "}"
+		}
+		if span.Bold {
+			text = "\\textbf{" + text + "}"
+		}
+		// Editor comments are only left in drafts; set them in the margin.
+		if span.Change == EditorComment {
+			text = "\\marginpar{\\footnotesize " + text + "}"
+		}
+		sb.WriteString(text)
+	}
+}
+
+// writeLatexNotes sets notes as a numbered list.
+func writeLatexNotes(sb *strings.Builder, notes []footnote) {
+	sb.WriteString("\\begin{enumerate}\n")
+	for _, note := range notes {
+		var text strings.Builder
+		writeLatexBlocks(&text, ParseMarkdown(note.text), SceneBreak{}.latex(), nil)
+		fmt.Fprintf(sb, "\\item[%d.] %s\n", note.number, strings.TrimSpace(text.String()))
+	}
+	sb.WriteString("\\end{enumerate}\n\n")
+}
+
+// writeLatexBookNotes sets the notes collected for the whole book as an
+// unnumbered chapter, grouped by chapter.
+func writeLatexBookNotes(sb *strings.Builder, notes []footnote, heading string) {
+	heading = latexEscape(heading)
+	fmt.Fprintf(sb, "\\chapter*{%s}\n\\addcontentsline{toc}{chapter}{%s}\n\n", heading, heading)
+	for _, group := range groupByChapter(notes) {
+		fmt.Fprintf(sb, "\\section*{%s}\n\n", latexEscape(group[0].label))
+		writeLatexNotes(sb, group)
+	}
+}
+
+var latexReplacer = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`&`, `\&`,
+	`#`, `\#`,
+	`%`, `\%`,
+	`_`, `\_`,
+	`^`, `\textasciicircum{}`,
+	`~`, `\textasciitilde{}`,
+)
+
+// latexEscape escapes the characters that have special meaning in LaTeX.
+func latexEscape(s string) string {
+	return latexReplacer.Replace(s)
+}
@@ -0,0 +1,141 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssembleLatex_ValidBook(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.tex")
+
+	fm, err := AssembleLatex(LatexConfig{
+		InputFile:  "testdata/valid_book.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, fm)
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	tex := string(content)
+
+	assert.Contains(t, tex, `\documentclass[11pt,openany]{book}`)
+	assert.Contains(t, tex, `paperwidth=6in,paperheight=9in`)
+	assert.Contains(t, tex, `\title{Test Book}`)
+	assert.Contains(t, tex, "\\maketitle\n\\tableofcontents\n")
+	assert.Contains(t, tex, `\usepackage{pifont}`)
+	assert.Contains(t, tex, `\chapter{Chapter One}`)
+	assert.Contains(t, tex, `\chapter*{}`)
+	assert.Contains(t, tex, "This is foo.\n\n\\scenebreak\n\nThis is baz.")
+	assert.NotContains(t, tex, "***")
+	assert.True(t, strings.HasSuffix(tex, "\\end{document}\n"))
+}
+
+func TestAssembleLatex_MemoirSettings(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.tex")
+
+	_, err := AssembleLatex(LatexConfig{
+		InputFile:  "testdata/book_with_latex.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	tex := string(content)
+
+	assert.Contains(t, tex, `\documentclass[12pt,openany]{memoir}`)
+	assert.Contains(t, tex, `\setstocksize{8.5in}{5.5in}`)
+	assert.Contains(t, tex, `\setmainfont{EB Garamond}`)
+	assert.NotContains(t, tex, "geometry")
+}
+
+func TestAssembleLatex_InvalidTrimSize(t *testing.T) {
+	fm, book, err := LoadBook("testdata/valid_book.yaml")
+	require.NoError(t, err)
+	book.Latex.TrimSize = "pocket"
+
+	_, err = latexPreamble(fm, book.Latex)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid trim size")
+}
+
+func TestAssembleLatex_InvalidDocumentClass(t *testing.T) {
+	_, err := latexPreamble(&FrontMatter{}, LatexSettings{DocumentClass: "article"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported LaTeX document class")
+}
+
+func TestParseTrimSize(t *testing.T) {
+	cases := []struct {
+		in, width, height string
+	}{
+		{"6x9", "6in", "9in"},
+		{"5.5in x 8.5in", "5.5in", "8.5in"},
+		{"148mm x 210mm", "148mm", "210mm"},
+		{"5 x 8 in", "5in", "8in"},
+	}
+	for _, c := range cases {
+		width, height, err := ParseTrimSize(c.in)
+		require.NoError(t, err, c.in)
+		assert.Equal(t, c.width, width, c.in)
+		assert.Equal(t, c.height, height, c.in)
+	}
+}
+
+func TestWriteLatexBlocks(t *testing.T) {
+	var sb strings.Builder
+	writeLatexBlocks(&sb, ParseMarkdown("She paid $5 & *left* 100% sure.\n\n> Quoted #1"), SceneBreak{}.latex(), nil)
+
+	tex := sb.String()
+	assert.Contains(t, tex, `She paid \$5 \& \emph{left} 100\% sure.`)
+	assert.Contains(t, tex, "\\begin{quote}\nQuoted \\#1\n\\end{quote}")
+}
+
+func TestLatexEscape(t *testing.T) {
+	assert.Equal(t, `a\_b \{c\} \textbackslash{} \textasciitilde{}`, latexEscape(`a_b {c} \ ~`))
+}
+
+func TestAssembleLatex_WithParts(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.tex")
+
+	_, err := AssembleLatex(LatexConfig{
+		InputFile:  "testdata/book_with_parts.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	tex := string(content)
+
+	assert.Contains(t, tex, `\bookpart{Part One: The Fall}{\emph{Everything falls.}}`)
+	assert.Contains(t, tex, `\bookpart{Part Two: The Rise}{}`)
+	assert.Less(t, strings.Index(tex, `\bookpart{Part Two`), strings.Index(tex, `\chapter{Chapter Two}`))
+}
+
+func TestAssembleLatex_WithMatterSections(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "book.tex")
+
+	_, err := AssembleLatex(LatexConfig{
+		InputFile:  "testdata/book_with_matter.yaml",
+		OutputFile: outFile,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	tex := string(content)
+
+	assert.Contains(t, tex, "\\begin{center}\n\\itshape\nFor the readers.")
+	assert.Contains(t, tex, "\\chapter*{Acknowledgments}\n\\addcontentsline{toc}{chapter}{Acknowledgments}")
+	assert.Less(t, strings.Index(tex, "For the readers."), strings.Index(tex, `\mainmatter`))
+	assert.Less(t, strings.Index(tex, `\mainmatter`), strings.Index(tex, `\chapter{Chapter One}`))
+	assert.Less(t, strings.Index(tex, `\chapter{Chapter Two}`), strings.Index(tex, `\backmatter`))
+	assert.Less(t, strings.Index(tex, `\backmatter`), strings.Index(tex, "Acknowledgments"))
+}
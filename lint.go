@@ -0,0 +1,283 @@
+package binder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LintIssue is a single problem found in a book spec or its manuscript.
+type LintIssue struct {
+	File    string
+	Line    int // 1-based line in File, or 0 when the issue concerns the whole file
+	Message string
+}
+
+func (li LintIssue) String() string {
+	if li.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", li.File, li.Line, li.Message)
+	}
+	return fmt.Sprintf("%s: %s", li.File, li.Message)
+}
+
+// LintBook checks a book spec and the manuscript it references, reporting
+// every problem found rather than stopping at the first: unknown YAML keys,
+// chapters without scenes, missing, empty or repeated scene files, broken
+// footnotes and markdown files under base_dir that no chapter references. An error is
+// returned only when the spec cannot be read or parsed at all.
+func LintBook(fileName string) ([]LintIssue, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var fmNode, specNode yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&fmNode); err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(&specNode); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	l := &linter{file: fileName}
+	l.checkKeys(documentRoot(&fmNode), reflect.TypeOf(FrontMatter{}))
+	l.checkKeys(documentRoot(&specNode), reflect.TypeOf(BookSpec{}))
+
+	_, book, err := LoadBook(fileName)
+	if err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			for _, msg := range typeErr.Errors {
+				l.addYAMLError(msg)
+			}
+		} else {
+			line := 0
+			var specErr *SpecError
+			if errors.As(err, &specErr) {
+				if node := mappingValue(mappingValue(documentRoot(&specNode), "book"), specErr.Key); node != nil {
+					line = node.Line
+				}
+			}
+			l.add(line, err.Error())
+		}
+		return l.sorted(), nil
+	}
+
+	// Chapter nodes are collected in the order GetChapters yields them, so
+	// the two can be walked side by side.
+	bookNode := mappingValue(documentRoot(&specNode), "book")
+	chapterNodes := sequenceItems(mappingValue(bookNode, "front_matter_sections"))
+	chapterNodes = append(chapterNodes, sequenceItems(mappingValue(bookNode, "chapters"))...)
+	for _, part := range sequenceItems(mappingValue(bookNode, "parts")) {
+		chapters := sequenceItems(mappingValue(part, "chapters"))
+		if len(chapters) == 0 {
+			l.add(part.Line, "part has no chapters")
+		}
+		chapterNodes = append(chapterNodes, chapters...)
+	}
+	chapterNodes = append(chapterNodes, sequenceItems(mappingValue(bookNode, "back_matter_sections"))...)
+
+	seen := map[string]int{}
+	i := 0
+	for chapter := range book.chapters(false) {
+		var node *yaml.Node
+		if i < len(chapterNodes) {
+			node = chapterNodes[i]
+		}
+		i++
+		var sceneNodes []*yaml.Node
+		if chapter.Matter == BodyMatter {
+			sceneNodes = sequenceItems(mappingValue(node, "scenes"))
+			if len(chapter.Scenes) == 0 {
+				l.add(nodeLine(node), fmt.Sprintf("%s has no scenes", chapter.Label()))
+			}
+		} else if file := mappingValue(node, "file"); file != nil {
+			sceneNodes = []*yaml.Node{file}
+		}
+		for j, scene := range chapter.Scenes {
+			line := nodeLine(node)
+			if j < len(sceneNodes) {
+				line = sceneNodes[j].Line
+			}
+			key := filepath.Clean(scene)
+			if first, ok := seen[key]; ok {
+				l.add(line, fmt.Sprintf("scene %s is already used on line %d", scene, first))
+				continue
+			}
+			seen[key] = line
+			parsed, err := ReadScene(scene)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				l.add(line, fmt.Sprintf("scene %s does not exist", scene))
+			case err != nil:
+				l.add(line, err.Error())
+			case strings.TrimSpace(parsed.Body) == "":
+				l.add(line, fmt.Sprintf("scene %s is empty", scene))
+			default:
+				for _, problem := range FootnoteProblems(parsed.Body) {
+					l.add(line, fmt.Sprintf("scene %s: %s", scene, problem))
+				}
+			}
+		}
+	}
+
+	issues := l.sorted()
+	orphans, err := orphanScenes(book.BaseDir, seen)
+	if err != nil {
+		return nil, err
+	}
+	for _, orphan := range orphans {
+		issues = append(issues, LintIssue{File: orphan, Message: "scene is not referenced by any chapter"})
+	}
+	return issues, nil
+}
+
+type linter struct {
+	file   string
+	issues []LintIssue
+}
+
+func (l *linter) add(line int, message string) {
+	l.issues = append(l.issues, LintIssue{File: l.file, Line: line, Message: message})
+}
+
+var yamlErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// addYAMLError records a yaml.v3 decoding error, lifting its "line N:"
+// prefix into the issue's line number.
+func (l *linter) addYAMLError(msg string) {
+	if m := yamlErrorLine.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		l.add(line, m[2])
+		return
+	}
+	l.add(0, msg)
+}
+
+func (l *linter) sorted() []LintIssue {
+	sort.SliceStable(l.issues, func(i, j int) bool { return l.issues[i].Line < l.issues[j].Line })
+	return l.issues
+}
+
+// checkKeys reports mapping keys that do not correspond to a field of t,
+// descending into nested structs and slices of structs.
+func (l *linter) checkKeys(node *yaml.Node, t reflect.Type) {
+	if node == nil {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				l.add(key.Line, fmt.Sprintf("unknown key %q", key.Value))
+				continue
+			}
+			l.checkKeys(value, field)
+		}
+	case reflect.Slice:
+		for _, item := range sequenceItems(node) {
+			l.checkKeys(item, t.Elem())
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of a struct type to their field types,
+// following yaml.v3's rule that untagged fields use their lower-cased name.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// orphanScenes returns the markdown files under baseDir that are not among
+// the referenced scenes, skipping hidden files and directories.
+func orphanScenes(baseDir string, referenced map[string]int) ([]string, error) {
+	info, err := os.Stat(baseDir)
+	if err != nil || !info.IsDir() {
+		return nil, nil
+	}
+	var orphans []string
+	err = filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != baseDir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+		if _, ok := referenced[filepath.Clean(path)]; !ok {
+			orphans = append(orphans, path)
+		}
+		return nil
+	})
+	return orphans, err
+}
+
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0]
+	}
+	return nil
+}
+
+// mappingValue returns the value stored under key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func sequenceItems(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+func nodeLine(node *yaml.Node) int {
+	if node == nil {
+		return 0
+	}
+	return node.Line
+}
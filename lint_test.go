@@ -0,0 +1,79 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintBook_ValidBook(t *testing.T) {
+	issues, err := LintBook("testdata/book_with_parts.yaml")
+	require.NoError(t, err)
+	for _, issue := range issues {
+		assert.Contains(t, issue.Message, "not referenced", "unexpected issue: %s", issue)
+	}
+}
+
+func TestLintBook_ReportsEveryProblem(t *testing.T) {
+	issues, err := LintBook("testdata/lint/book.yaml")
+	require.NoError(t, err)
+
+	var lines []string
+	for _, issue := range issues {
+		lines = append(lines, issue.String())
+	}
+	base := filepath.Join("testdata", "lint", "manuscript")
+	assert.Equal(t, []string{
+		`testdata/lint/book.yaml:3: unknown key "autor"`,
+		"testdata/lint/book.yaml:10: scene " + filepath.Join(base, "missing.md") + " does not exist",
+		"testdata/lint/book.yaml:11: Chapter Two has no scenes",
+		`testdata/lint/book.yaml:12: unknown key "nmae"`,
+		"testdata/lint/book.yaml:14: scene " + filepath.Join(base, "blank.md") + " is empty",
+		"testdata/lint/book.yaml:15: scene " + filepath.Join(base, "one.md") + " is already used on line 9",
+		filepath.Join(base, "extra", "stray.md") + ": scene is not referenced by any chapter",
+		filepath.Join(base, "orphan.md") + ": scene is not referenced by any chapter",
+	}, lines)
+}
+
+func TestLintBook_TypeErrors(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "book.yaml")
+	require.NoError(t, os.WriteFile(spec, []byte("---\ntitle: T\n---\nbook:\n  chapters:\n    - scenes: \"foo\"\n"), 0644))
+
+	issues, err := LintBook(spec)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 6, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "cannot unmarshal")
+}
+
+func TestLintBook_InvalidNumbering(t *testing.T) {
+	issues, err := LintBook("testdata/invalid_numbering.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, issues)
+	assert.Contains(t, issues[len(issues)-1].Message, "numbering")
+	assert.Positive(t, issues[len(issues)-1].Line)
+}
+
+func TestLintBook_InvalidSettingLine(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "book.yaml")
+	require.NoError(t, os.WriteFile(spec, []byte("---\ntitle: T\n---\nbook:\n  chapters:\n    - scenes: [foo]\n  footnotes: margin\n"), 0644))
+
+	issues, err := LintBook(spec)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 7, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "unknown footnote placement")
+}
+
+func TestLintBook_MissingFile(t *testing.T) {
+	_, err := LintBook("testdata/nonexistent.yaml")
+	assert.Error(t, err)
+}
+
+func TestLintIssue_String(t *testing.T) {
+	assert.Equal(t, "book.yaml:4: oops", LintIssue{File: "book.yaml", Line: 4, Message: "oops"}.String())
+	assert.Equal(t, "scene.md: oops", LintIssue{File: "scene.md", Message: "oops"}.String())
+}
@@ -0,0 +1,275 @@
+package binder
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/divan/num2words"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// Locale holds the language-specific pieces of chapter headings.
+type Locale struct {
+	Tag          language.Tag
+	ChapterLabel string // the word for "Chapter", e.g. "Capítulo"
+	NotesLabel   string // the heading of collected endnotes, e.g. "Notas"; "Notes" if empty
+	// ContentsLabel heads the table of contents, e.g. "Índice", and
+	// TitlePageLabel names the title page among EPUB landmarks; "Contents"
+	// and "Title Page" if empty.
+	ContentsLabel, TitlePageLabel string
+	// InterludeLabel names an unnumbered, untitled chapter, e.g.
+	// "Interludio"; "Interlude" if empty.
+	InterludeLabel string
+	// NumberWords spells out a chapter number in lower case. It returns
+	// false for numbers it cannot spell, in which case digits are used.
+	NumberWords func(n int) (string, bool)
+	// MinorWords stay lower case when headings are title-cased, e.g. the
+	// "y" in "Treinta y Uno".
+	MinorWords []string
+}
+
+// Title title-cases s using the locale's casing rules.
+func (l Locale) Title(s string) string {
+	titled := cases.Title(l.Tag).String(s)
+	if len(l.MinorWords) == 0 {
+		return titled
+	}
+	words := strings.Split(titled, " ")
+	for i, word := range words {
+		if i == 0 {
+			continue
+		}
+		for _, minor := range l.MinorWords {
+			if strings.EqualFold(word, minor) {
+				words[i] = minor
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// contents returns the heading of the table of contents.
+func (l Locale) contents() string {
+	return cmp.Or(l.ContentsLabel, "Contents")
+}
+
+// titlePage returns the name of the title page.
+func (l Locale) titlePage() string {
+	return cmp.Or(l.TitlePageLabel, "Title Page")
+}
+
+// Spell returns n spelled out in title case, or in digits when the locale
+// cannot spell it.
+func (l Locale) Spell(n int) string {
+	if l.NumberWords != nil {
+		if words, ok := l.NumberWords(n); ok {
+			return l.Title(words)
+		}
+	}
+	return strconv.Itoa(n)
+}
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]Locale{
+		"en": {
+			Tag:            language.English,
+			ChapterLabel:   "Chapter",
+			NotesLabel:     "Notes",
+			InterludeLabel: "Interlude",
+			ContentsLabel:  "Contents",
+			TitlePageLabel: "Title Page",
+			NumberWords:    func(n int) (string, bool) { return num2words.Convert(n), true },
+		},
+		"uk": {
+			Tag:            language.Ukrainian,
+			ChapterLabel:   "Розділ",
+			NotesLabel:     "Примітки",
+			InterludeLabel: "Інтерлюдія",
+			ContentsLabel:  "Зміст",
+			TitlePageLabel: "Титульна сторінка",
+			NumberWords: func(n int) (string, bool) {
+				words, err := num2words.ConvertLang(n, "uk")
+				return words, err == nil
+			},
+		},
+		"es": {
+			Tag:            language.Spanish,
+			ChapterLabel:   "Capítulo",
+			NotesLabel:     "Notas",
+			InterludeLabel: "Interludio",
+			ContentsLabel:  "Índice",
+			TitlePageLabel: "Portada",
+			NumberWords:    spanishNumberWords,
+			MinorWords:     []string{"y"},
+		},
+		"fr": {
+			Tag:            language.French,
+			ChapterLabel:   "Chapitre",
+			NotesLabel:     "Notes",
+			InterludeLabel: "Interlude",
+			ContentsLabel:  "Table des matières",
+			TitlePageLabel: "Page de titre",
+			NumberWords:    frenchNumberWords,
+			MinorWords:     []string{"et"},
+		},
+		"de": {
+			Tag:            language.German,
+			ChapterLabel:   "Kapitel",
+			NotesLabel:     "Anmerkungen",
+			InterludeLabel: "Zwischenspiel",
+			ContentsLabel:  "Inhalt",
+			TitlePageLabel: "Titelseite",
+			NumberWords:    germanNumberWords,
+		},
+	}
+)
+
+// RegisterLocale adds or replaces the locale used for a language code such
+// as "it" or "pt". Codes are matched on their base language, so a locale
+// registered as "pt" also serves "pt-BR".
+func RegisterLocale(code string, locale Locale) {
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[strings.ToLower(code)] = locale
+}
+
+// LookupLocale returns the locale for a language code, falling back from a
+// regional tag like "es-MX" to its base language. An empty code selects
+// English. The boolean is false when no locale is registered, in which case
+// an English-labelled locale that numbers in digits is returned.
+func LookupLocale(code string) (Locale, bool) {
+	if code == "" {
+		code = "en"
+	}
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	code = strings.ToLower(code)
+	if locale, ok := locales[code]; ok {
+		return locale, true
+	}
+	tag, err := language.Parse(code)
+	if err == nil {
+		base, _ := tag.Base()
+		if locale, ok := locales[base.String()]; ok {
+			return locale, true
+		}
+	} else {
+		tag = language.Und
+	}
+	return Locale{Tag: tag, ChapterLabel: "Chapter"}, false
+}
+
+func spanishNumberWords(n int) (string, bool) {
+	units := []string{"cero", "uno", "dos", "tres", "cuatro", "cinco", "seis", "siete", "ocho", "nueve",
+		"diez", "once", "doce", "trece", "catorce", "quince", "dieciséis", "diecisiete", "dieciocho", "diecinueve",
+		"veinte", "veintiuno", "veintidós", "veintitrés", "veinticuatro", "veinticinco", "veintiséis", "veintisiete", "veintiocho", "veintinueve"}
+	tens := []string{"", "", "", "treinta", "cuarenta", "cincuenta", "sesenta", "setenta", "ochenta", "noventa"}
+	hundreds := []string{"", "ciento", "doscientos", "trescientos", "cuatrocientos", "quinientos", "seiscientos", "setecientos", "ochocientos", "novecientos"}
+	below100 := func(n int) string {
+		if n < 30 {
+			return units[n]
+		}
+		if n%10 == 0 {
+			return tens[n/10]
+		}
+		return tens[n/10] + " y " + units[n%10]
+	}
+	switch {
+	case n < 0 || n > 999:
+		return "", false
+	case n < 100:
+		return below100(n), true
+	case n == 100:
+		return "cien", true
+	case n%100 == 0:
+		return hundreds[n/100], true
+	default:
+		return hundreds[n/100] + " " + below100(n%100), true
+	}
+}
+
+func frenchNumberWords(n int) (string, bool) {
+	units := []string{"zéro", "un", "deux", "trois", "quatre", "cinq", "six", "sept", "huit", "neuf",
+		"dix", "onze", "douze", "treize", "quatorze", "quinze", "seize", "dix-sept", "dix-huit", "dix-neuf"}
+	tens := []string{"", "", "vingt", "trente", "quarante", "cinquante", "soixante"}
+	below100 := func(n int) string {
+		switch {
+		case n < 20:
+			return units[n]
+		case n < 70:
+			t, u := n/10, n%10
+			switch u {
+			case 0:
+				return tens[t]
+			case 1:
+				return tens[t] + " et un"
+			default:
+				return tens[t] + "-" + units[u]
+			}
+		case n < 80:
+			if n == 71 {
+				return "soixante et onze"
+			}
+			return "soixante-" + units[n-60]
+		case n == 80:
+			return "quatre-vingts"
+		default:
+			return "quatre-vingt-" + units[n-80]
+		}
+	}
+	switch {
+	case n < 0 || n > 999:
+		return "", false
+	case n < 100:
+		return below100(n), true
+	}
+	h, rest := n/100, n%100
+	prefix := "cent"
+	if h > 1 {
+		prefix = units[h] + " cent"
+		if rest == 0 {
+			prefix += "s"
+		}
+	}
+	if rest == 0 {
+		return prefix, true
+	}
+	return prefix + " " + below100(rest), true
+}
+
+func germanNumberWords(n int) (string, bool) {
+	units := []string{"null", "eins", "zwei", "drei", "vier", "fünf", "sechs", "sieben", "acht", "neun",
+		"zehn", "elf", "zwölf", "dreizehn", "vierzehn", "fünfzehn", "sechzehn", "siebzehn", "achtzehn", "neunzehn"}
+	tens := []string{"", "", "zwanzig", "dreißig", "vierzig", "fünfzig", "sechzig", "siebzig", "achtzig", "neunzig"}
+	below100 := func(n int) string {
+		switch {
+		case n < 20:
+			return units[n]
+		case n%10 == 0:
+			return tens[n/10]
+		case n%10 == 1:
+			return "einund" + tens[n/10]
+		default:
+			return units[n%10] + "und" + tens[n/10]
+		}
+	}
+	switch {
+	case n < 0 || n > 999:
+		return "", false
+	case n < 100:
+		return below100(n), true
+	}
+	h, rest := n/100, n%100
+	prefix := "einhundert"
+	if h > 1 {
+		prefix = units[h] + "hundert"
+	}
+	if rest == 0 {
+		return prefix, true
+	}
+	return prefix + below100(rest), true
+}
@@ -0,0 +1,164 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestLookupLocale(t *testing.T) {
+	en, ok := LookupLocale("")
+	require.True(t, ok)
+	assert.Equal(t, "Chapter", en.ChapterLabel)
+
+	es, ok := LookupLocale("es-MX")
+	require.True(t, ok, "regional tags fall back to the base language")
+	assert.Equal(t, "Capítulo", es.ChapterLabel)
+
+	fr, ok := LookupLocale("FR")
+	require.True(t, ok)
+	assert.Equal(t, "Chapitre", fr.ChapterLabel)
+
+	unknown, ok := LookupLocale("sw")
+	assert.False(t, ok)
+	assert.Equal(t, "Chapter", unknown.ChapterLabel)
+	assert.Equal(t, "7", unknown.Spell(7), "unknown languages number in digits")
+}
+
+func TestRegisterLocale(t *testing.T) {
+	RegisterLocale("it", Locale{
+		Tag:          language.Italian,
+		ChapterLabel: "Capitolo",
+		NumberWords: func(n int) (string, bool) {
+			words := map[int]string{1: "uno", 2: "due"}
+			w, ok := words[n]
+			return w, ok
+		},
+	})
+	t.Cleanup(func() {
+		localesMu.Lock()
+		delete(locales, "it")
+		localesMu.Unlock()
+	})
+
+	book := &Book{
+		BaseDir:  "m",
+		Language: "it-IT",
+		Chapters: []Chapter{{Scenes: []string{"a"}}, {Scenes: []string{"b"}}, {Scenes: []string{"c"}}},
+	}
+	assert.Equal(t, []string{"Capitolo Uno", "Capitolo Due", "Capitolo 3"}, collectHeadings(book))
+}
+
+func TestLocale_SpellAndTitle(t *testing.T) {
+	es, _ := LookupLocale("es")
+	assert.Equal(t, "Uno", es.Spell(1))
+	assert.Equal(t, "Veintiuno", es.Spell(21))
+	assert.Equal(t, "Treinta y Uno", es.Spell(31))
+	assert.Equal(t, "Cien", es.Spell(100))
+	assert.Equal(t, "Ciento Dos", es.Spell(102))
+	assert.Equal(t, "1000", es.Spell(1000))
+
+	fr, _ := LookupLocale("fr")
+	assert.Equal(t, "Un", fr.Spell(1))
+	assert.Equal(t, "Vingt et Un", fr.Spell(21))
+	assert.Equal(t, "Soixante et Onze", fr.Spell(71))
+	assert.Equal(t, "Quatre-Vingts", fr.Spell(80))
+	assert.Equal(t, "Quatre-Vingt-Dix-Neuf", fr.Spell(99))
+	assert.Equal(t, "Deux Cents", fr.Spell(200))
+
+	de, _ := LookupLocale("de")
+	assert.Equal(t, "Eins", de.Spell(1))
+	assert.Equal(t, "Einundzwanzig", de.Spell(21))
+	assert.Equal(t, "Einhunderteins", de.Spell(101))
+	assert.Equal(t, "Dreihundertzwölf", de.Spell(312))
+}
+
+func TestNumberWords_SpanishFrenchGerman(t *testing.T) {
+	cases := []struct {
+		fn       func(int) (string, bool)
+		n        int
+		expected string
+	}{
+		{spanishNumberWords, 16, "dieciséis"},
+		{spanishNumberWords, 45, "cuarenta y cinco"},
+		{spanishNumberWords, 500, "quinientos"},
+		{spanishNumberWords, 999, "novecientos noventa y nueve"},
+		{frenchNumberWords, 17, "dix-sept"},
+		{frenchNumberWords, 70, "soixante-dix"},
+		{frenchNumberWords, 81, "quatre-vingt-un"},
+		{frenchNumberWords, 101, "cent un"},
+		{germanNumberWords, 30, "dreißig"},
+		{germanNumberWords, 57, "siebenundfünfzig"},
+		{germanNumberWords, 200, "zweihundert"},
+	}
+	for _, c := range cases {
+		words, ok := c.fn(c.n)
+		require.True(t, ok, c.expected)
+		assert.Equal(t, c.expected, words)
+	}
+}
+
+func TestBook_GetChapters_Localized(t *testing.T) {
+	chapters := []Chapter{
+		{Name: "el comienzo", Scenes: []string{"a"}},
+		{Scenes: []string{"b"}},
+		{Scenes: []string{"c"}},
+	}
+
+	es := &Book{BaseDir: "m", Language: "es", Chapters: chapters}
+	assert.Equal(t, []string{"El Comienzo", "Capítulo Uno", "Capítulo Dos"}, collectHeadings(es))
+
+	fr := &Book{BaseDir: "m", Language: "fr", Chapters: chapters[1:]}
+	assert.Equal(t, []string{"Chapitre Un", "Chapitre Deux"}, collectHeadings(fr))
+
+	de := &Book{BaseDir: "m", Language: "de", Chapters: chapters[1:]}
+	assert.Equal(t, []string{"Kapitel Eins", "Kapitel Zwei"}, collectHeadings(de))
+}
+
+func TestBook_GetChapters_LocalizedTemplateAndLabel(t *testing.T) {
+	book := &Book{
+		BaseDir:   "m",
+		Language:  "fr",
+		Chapters:  []Chapter{{Name: "le départ", Scenes: []string{"a"}}},
+		Numbering: Numbering{Template: "{{.Label}} {{.Number}} : {{.Name}}"},
+	}
+	assert.Equal(t, []string{"Chapitre Un : Le Départ"}, collectHeadings(book))
+
+	book.Numbering = Numbering{Style: NumberRoman, Label: "Livre"}
+	book.Chapters = []Chapter{{Scenes: []string{"a"}}}
+	assert.Equal(t, []string{"Livre I"}, collectHeadings(book))
+}
+
+func TestLoadBook_LanguageFromFrontMatter(t *testing.T) {
+	fm, book, err := LoadBook("testdata/book_in_spanish.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "es", fm.Language)
+	assert.Equal(t, "es", book.Language)
+	assert.Equal(t, []string{"Capítulo Uno", "Capítulo Dos"}, collectHeadings(book))
+}
+
+func TestAssemble_LocalizedLabels(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := AssembleHTML(HTMLConfig{InputFile: "testdata/book_in_spanish.yaml", OutputFile: filepath.Join(dir, "book.html")})
+	require.NoError(t, err)
+	page, err := os.ReadFile(filepath.Join(dir, "book.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "<h2>Índice</h2>")
+
+	_, err = AssembleEpub(EpubConfig{InputFile: "testdata/book_in_spanish.yaml", OutputFile: filepath.Join(dir, "book.epub")})
+	require.NoError(t, err)
+	nav := readZipEntry(t, filepath.Join(dir, "book.epub"), "OEBPS/nav.xhtml")
+	assert.Contains(t, nav, "<title>Índice</title>")
+	assert.Contains(t, nav, "<h1>Índice</h1>")
+	assert.Contains(t, nav, `href="text/title.xhtml">Portada</a>`)
+
+	_, err = AssembleDocx(DocxConfig{InputFile: "testdata/book_in_spanish.yaml", OutputFile: filepath.Join(dir, "book.docx")})
+	require.NoError(t, err)
+	assert.Contains(t, readZipEntry(t, filepath.Join(dir, "book.docx"), "word/styles.xml"), `<w:lang w:val="es"/>`)
+}
@@ -0,0 +1,325 @@
+package binder
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// BlockKind identifies the kind of a parsed markdown block.
+type BlockKind int
+
+const (
+	ParagraphBlock BlockKind = iota
+	HeadingBlock
+	QuoteBlock
+	SceneBreakBlock
+)
+
+// Span is a run of inline text sharing the same emphasis.
+type Span struct {
+	Text   string
+	Italic bool
+	Bold   bool
+	// Change is set on the spans of CriticMarkup changes, which are only
+	// left in the text for review copies, and on editor comments.
+	Change Change
+	// Note is set on footnote references to the ID of the note, with Text
+	// the number shown.
+	Note string
+}
+
+// Block is a single block-level element of a scene: a paragraph, a heading,
+// a block quote or a scene break.
+type Block struct {
+	Kind  BlockKind
+	Level int // heading level, only set for HeadingBlock
+	Spans []Span
+}
+
+// ParseMarkdown splits scene markdown into blocks. It understands the subset
+// of markdown used in prose manuscripts: paragraphs, ATX headings, block
+// quotes, thematic breaks (treated as scene breaks) and inline emphasis.
+func ParseMarkdown(text string) []Block {
+	var blocks []Block
+	var para []string
+	kind := ParagraphBlock
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, Block{Kind: kind, Spans: parseInline(strings.Join(para, " "))})
+		}
+		para = nil
+		kind = ParagraphBlock
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case isThematicBreak(trimmed):
+			flush()
+			blocks = append(blocks, Block{Kind: SceneBreakBlock})
+		case headingLevel(trimmed) > 0:
+			flush()
+			level := headingLevel(trimmed)
+			heading := strings.TrimSpace(strings.TrimRight(trimmed[level:], "#"))
+			blocks = append(blocks, Block{Kind: HeadingBlock, Level: level, Spans: parseInline(heading)})
+		case strings.HasPrefix(trimmed, ">"):
+			if kind != QuoteBlock {
+				flush()
+				kind = QuoteBlock
+			}
+			para = append(para, strings.TrimSpace(strings.TrimPrefix(trimmed, ">")))
+		default:
+			if kind == QuoteBlock {
+				flush()
+			}
+			para = append(para, trimmed)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// PlainText returns the text of the block without any emphasis.
+func (b Block) PlainText() string {
+	var sb strings.Builder
+	for _, span := range b.Spans {
+		sb.WriteString(span.Text)
+	}
+	return sb.String()
+}
+
+// isThematicBreak reports whether line is a markdown thematic break such as
+// "***", "* * *", "---" or "___".
+func isThematicBreak(line string) bool {
+	var marker rune
+	count := 0
+	for _, r := range line {
+		switch {
+		case r == ' ' || r == '\t':
+			continue
+		case marker == 0 && (r == '*' || r == '-' || r == '_'):
+			marker = r
+			count++
+		case r == marker:
+			count++
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+// headingLevel returns the level of an ATX heading line, or 0 if the line is
+// not a heading.
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0
+	}
+	if level < len(line) && line[level] != ' ' {
+		return 0
+	}
+	return level
+}
+
+// parseInline splits text into spans on *emphasis*, _emphasis_, **strong**
+// and __strong__ markers, on CriticMarkup changes and on the footnote
+// references footnoter writes. Markers without a
+// matching closer are kept as literal text, as are backslash-escaped
+// punctuation characters.
+func parseInline(text string) []Span {
+	var spans []Span
+	var buf strings.Builder
+	italic, bold := false, false
+	flush := func() {
+		if buf.Len() > 0 {
+			spans = append(spans, Span{Text: buf.String(), Italic: italic, Bold: bold})
+			buf.Reset()
+		}
+	}
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("\\`*_{}[]()#+-.!>~", text[i+1]) >= 0:
+			buf.WriteByte(text[i+1])
+			i += 2
+		case c == '[' && strings.HasPrefix(text[i:], "[^"):
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 || !isFootnoteID(text[i+2:i+end]) {
+				buf.WriteByte(c)
+				i++
+				break
+			}
+			flush()
+			id := text[i+2 : i+end]
+			spans = append(spans, Span{Text: footnoteNumber(id), Italic: italic, Bold: bold, Note: id})
+			i += end + 1
+		case c == '{':
+			changed, n, ok := parseCritic(text[i:], italic, bold)
+			if !ok {
+				buf.WriteByte(c)
+				i++
+				break
+			}
+			flush()
+			spans = append(spans, changed...)
+			i += n
+		case (c == '*' || c == '_') && i+1 < len(text) && text[i+1] == c:
+			marker := text[i : i+2]
+			if bold || strings.Contains(text[i+2:], marker) {
+				flush()
+				bold = !bold
+			} else {
+				buf.WriteString(marker)
+			}
+			i += 2
+		case c == '*' || c == '_':
+			if c == '_' && !italic && i > 0 && isWordByte(text[i-1]) {
+				buf.WriteByte(c)
+			} else if italic || strings.IndexByte(text[i+1:], c) >= 0 {
+				flush()
+				italic = !italic
+			} else {
+				buf.WriteByte(c)
+			}
+			i++
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return spans
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// writeHTMLBlocks renders blocks as HTML that is also well-formed XHTML,
+// writing sceneBreak for scene breaks.
+func writeHTMLBlocks(sb *strings.Builder, blocks []Block, sceneBreak string) {
+	for _, block := range blocks {
+		switch block.Kind {
+		case HeadingBlock:
+			level := max(block.Level, 2)
+			fmt.Fprintf(sb, "<h%d>", level)
+			writeHTMLSpans(sb, block.Spans)
+			fmt.Fprintf(sb, "</h%d>\n", level)
+		case QuoteBlock:
+			sb.WriteString("<blockquote><p>")
+			writeHTMLSpans(sb, block.Spans)
+			sb.WriteString("</p></blockquote>\n")
+		case SceneBreakBlock:
+			sb.WriteString(sceneBreak)
+		default:
+			sb.WriteString("<p>")
+			writeHTMLSpans(sb, block.Spans)
+			sb.WriteString("</p>\n")
+		}
+	}
+}
+
+func writeHTMLSpans(sb *strings.Builder, spans []Span) {
+	for _, span := range spans {
+		text := html.EscapeString(span.Text)
+		if span.Italic {
+			text = "<em>" + text + "</em>"
+		}
+		if span.Bold {
+			text = "<strong>" + text + "</strong>"
+		}
+		if span.Note != "" {
+			text = fmt.Sprintf("<a href=\"#fn-%s\" id=\"fnref-%s\" role=\"doc-noteref\"><sup>%s</sup></a>", span.Note, span.Note, text)
+		}
+		switch span.Change {
+		case Inserted:
+			text = "<ins>" + text + "</ins>"
+		case Deleted:
+			text = "<del>" + text + "</del>"
+		case Highlighted:
+			text = "<mark>" + text + "</mark>"
+		case EditorComment:
+			text = "<span class=\"critic-comment\">" + text + "</span>"
+		}
+		sb.WriteString(text)
+	}
+}
+
+// renderChapterHTML reads a chapter's scenes and renders them as HTML body
+// content, separating scenes with scene breaks. The chapter heading itself
+// is left to the caller. The chapter's footnotes follow its text unless
+// they are collected for the whole book, when they are returned instead.
+func renderChapterHTML(chapter IteratedChapter, options SceneOptions, notes *footnoter) (string, []footnote, error) {
+	sceneBreak, err := chapter.SceneBreak.html()
+	if err != nil {
+		return "", nil, err
+	}
+	notes.startChapter(chapter.Label())
+	var chapterNotes []footnote
+	var sb strings.Builder
+	scenes, err := chapter.LoadScenes()
+	if err != nil {
+		return "", nil, err
+	}
+	for i := range scenes {
+		text := &scenes[i]
+		if i > 0 {
+			sb.WriteString(sceneBreak)
+		}
+		body, sceneNotes := notes.scene(options.body(text))
+		chapterNotes = append(chapterNotes, sceneNotes...)
+		writeHTMLBlocks(&sb, ParseMarkdown(body), sceneBreak)
+	}
+	if notes.mode == FootnotesBook || len(chapterNotes) == 0 {
+		return sb.String(), chapterNotes, nil
+	}
+	sb.WriteString("<div class=\"notes\" role=\"doc-endnotes\">\n")
+	if notes.mode == FootnotesChapter {
+		fmt.Fprintf(&sb, "<h2>%s</h2>\n", html.EscapeString(notes.heading))
+	}
+	writeHTMLNotes(&sb, chapterNotes)
+	sb.WriteString("</div>\n")
+	return sb.String(), nil, nil
+}
+
+// writeHTMLNotes writes notes as a numbered list, each linking back to its
+// reference.
+func writeHTMLNotes(sb *strings.Builder, notes []footnote) {
+	sb.WriteString("<ol class=\"notes\">\n")
+	for _, note := range notes {
+		var text strings.Builder
+		writeHTMLBlocks(&text, ParseMarkdown(note.text), htmlSceneBreak)
+		body := text.String()
+		backlink := fmt.Sprintf(" <a href=\"%s#fnref-%s\" role=\"doc-backlink\">\u21a9</a>", note.page, note.id)
+		if end := strings.LastIndex(body, "</p>"); end >= 0 {
+			body = body[:end] + backlink + body[end:]
+		} else {
+			body += "<p>" + backlink + "</p>\n"
+		}
+		fmt.Fprintf(sb, "<li id=\"fn-%s\" value=\"%d\">%s</li>\n", note.id, note.number, body)
+	}
+	sb.WriteString("</ol>\n")
+}
+
+// renderNotesHTML renders the notes collected for the whole book, grouped
+// by chapter. The heading is left to the caller.
+func renderNotesHTML(notes []footnote) string {
+	var sb strings.Builder
+	for _, group := range groupByChapter(notes) {
+		fmt.Fprintf(&sb, "<h2>%s</h2>\n", html.EscapeString(group[0].label))
+		writeHTMLNotes(&sb, group)
+	}
+	return sb.String()
+}
+
+// linkNotes points the note references in a chapter rendered by
+// renderChapterHTML at page, where the book's notes are collected.
+func linkNotes(body, page string) string {
+	return strings.ReplaceAll(body, `href="#fn-`, `href="`+page+`#fn-`)
+}
@@ -0,0 +1,68 @@
+package binder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarkdown_Paragraphs(t *testing.T) {
+	blocks := ParseMarkdown("First line\ncontinues here.\n\nSecond paragraph.\n")
+
+	require.Len(t, blocks, 2)
+	assert.Equal(t, ParagraphBlock, blocks[0].Kind)
+	assert.Equal(t, "First line continues here.", blocks[0].PlainText())
+	assert.Equal(t, "Second paragraph.", blocks[1].PlainText())
+}
+
+func TestParseMarkdown_HeadingsQuotesAndBreaks(t *testing.T) {
+	text := "## The Door ##\n\nShe knocked.\n\n* * *\n\n> Who goes there?\n> Nobody.\n\nSilence."
+	blocks := ParseMarkdown(text)
+
+	require.Len(t, blocks, 5)
+	assert.Equal(t, HeadingBlock, blocks[0].Kind)
+	assert.Equal(t, 2, blocks[0].Level)
+	assert.Equal(t, "The Door", blocks[0].PlainText())
+	assert.Equal(t, ParagraphBlock, blocks[1].Kind)
+	assert.Equal(t, SceneBreakBlock, blocks[2].Kind)
+	assert.Equal(t, QuoteBlock, blocks[3].Kind)
+	assert.Equal(t, "Who goes there? Nobody.", blocks[3].PlainText())
+	assert.Equal(t, ParagraphBlock, blocks[4].Kind)
+}
+
+func TestParseMarkdown_HashtagIsNotHeading(t *testing.T) {
+	blocks := ParseMarkdown("#hashtag")
+
+	require.Len(t, blocks, 1)
+	assert.Equal(t, ParagraphBlock, blocks[0].Kind)
+}
+
+func TestParseInline_Emphasis(t *testing.T) {
+	spans := parseInline("She *never* said **that**.")
+
+	require.Len(t, spans, 5)
+	assert.Equal(t, Span{Text: "She "}, spans[0])
+	assert.Equal(t, Span{Text: "never", Italic: true}, spans[1])
+	assert.Equal(t, Span{Text: " said "}, spans[2])
+	assert.Equal(t, Span{Text: "that", Bold: true}, spans[3])
+	assert.Equal(t, Span{Text: "."}, spans[4])
+}
+
+func TestParseInline_UnmatchedAndEscapedMarkers(t *testing.T) {
+	assert.Equal(t, []Span{{Text: "5 * 3 = 15"}}, parseInline("5 * 3 = 15"))
+	assert.Equal(t, []Span{{Text: "*literal*"}}, parseInline(`\*literal\*`))
+	assert.Equal(t, []Span{{Text: "snake_case_name"}}, parseInline("snake_case_name"))
+}
+
+func TestWriteHTMLBlocks(t *testing.T) {
+	var sb strings.Builder
+	writeHTMLBlocks(&sb, ParseMarkdown("# Aside\n\nSome *quiet* words & more.\n\n***\n\n> A quote."), htmlSceneBreak)
+
+	html := sb.String()
+	assert.Contains(t, html, "<h2>Aside</h2>")
+	assert.Contains(t, html, "<p>Some <em>quiet</em> words &amp; more.</p>")
+	assert.Contains(t, html, `<hr class="scene-break"/>`)
+	assert.Contains(t, html, "<blockquote><p>A quote.</p></blockquote>")
+}
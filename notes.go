@@ -0,0 +1,145 @@
+package binder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Kinds of author note.
+const (
+	NoteComment     = "comment"     // <!-- an HTML comment -->
+	NoteBracket     = "note"        // [[a wiki-style note]]
+	NoteCritic      = "critic"      // {>> a CriticMarkup comment <<}
+	NotePlaceholder = "placeholder" // TK, for "to come"
+)
+
+// Note is an annotation an author left in a scene for themselves. Notes
+// are stripped from assembled output unless drafts are kept.
+type Note struct {
+	File string
+	Line int // 1-based line in File on which the note starts
+	Kind string
+	Text string // the note without its delimiters; empty for placeholders
+}
+
+func (n Note) String() string {
+	if n.Text == "" {
+		return fmt.Sprintf("%s:%d: %s", n.File, n.Line, n.Kind)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", n.File, n.Line, n.Kind, n.Text)
+}
+
+// notePattern matches every kind of note. The submatches hold the text of
+// a comment, a bracketed note and a CriticMarkup comment; a placeholder has
+// none. TK is only a placeholder as a word of its own, so "TKO" is not one.
+// Code blocks and inline code are matched too, as typographyProtected does,
+// so that literal text in code is passed over; isCode tells them apart.
+var notePattern = regexp.MustCompile("(?s)```.*?```|`[^`]*`|<!--(.*?)-->|\\[\\[(.*?)\\]\\]|\\{>>(.*?)<<\\}|\\bTK\\b")
+
+// isCode reports whether a notePattern match is code rather than a note.
+func isCode(match string) bool {
+	return strings.HasPrefix(match, "`")
+}
+
+// FindNotes lists the notes in a scene's body, in order.
+func FindNotes(scene *Scene) []Note {
+	var notes []Note
+	for _, m := range notePattern.FindAllStringSubmatchIndex(scene.Body, -1) {
+		if isCode(scene.Body[m[0]:m[1]]) {
+			continue
+		}
+		note := Note{
+			File: scene.Path,
+			Line: scene.BodyLine + strings.Count(scene.Body[:m[0]], "\n"),
+			Kind: NotePlaceholder,
+		}
+		for i, kind := range []string{NoteComment, NoteBracket, NoteCritic} {
+			if start := m[2+2*i]; start >= 0 {
+				note.Kind = kind
+				note.Text = strings.Join(strings.Fields(scene.Body[start:m[3+2*i]]), " ")
+			}
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// StripNotes removes notes from scene text. A line holding nothing but
+// notes is removed with the blank line after it; elsewhere the space
+// around a note is closed up so that no double spaces are left behind.
+func StripNotes(text string) string {
+	return stripNotes(text, false)
+}
+
+// stripNotes is StripNotes, optionally keeping CriticMarkup comments for a
+// review copy.
+func stripNotes(text string, keepCritic bool) string {
+	const mark = "\x00"
+	marked := notePattern.ReplaceAllStringFunc(text, func(note string) string {
+		if isCode(note) || keepCritic && strings.HasPrefix(note, "{>>") {
+			return note
+		}
+		return mark
+	})
+	if marked == text {
+		return text
+	}
+	var sb strings.Builder
+	dropped := false
+	for line := range strings.Lines(marked) {
+		blank := strings.TrimSpace(line) == ""
+		if dropped && blank && (sb.Len() == 0 || strings.HasSuffix(sb.String(), "\n\n")) {
+			dropped = false
+			continue
+		}
+		dropped = false
+		if !strings.Contains(line, mark) {
+			sb.WriteString(line)
+			continue
+		}
+		if strings.TrimSpace(strings.ReplaceAll(line, mark, "")) == "" {
+			dropped = true
+			continue
+		}
+		last := 0
+		for _, m := range noteGapPattern.FindAllStringIndex(line, -1) {
+			sb.WriteString(line[last:m[0]])
+			gap := line[m[0]:m[1]]
+			spaceBefore := strings.HasPrefix(gap, " ") || strings.HasPrefix(gap, "\t")
+			spaceAfter := strings.HasSuffix(gap, " ") || strings.HasSuffix(gap, "\t")
+			atEnd := m[1] == len(line) || strings.ContainsRune("\r\n.,;:!?)", rune(line[m[1]]))
+			if spaceBefore && !atEnd || spaceAfter && m[0] > 0 && !spaceBefore {
+				sb.WriteString(" ")
+			}
+			last = m[1]
+		}
+		sb.WriteString(line[last:])
+	}
+	return sb.String()
+}
+
+// noteGapPattern matches stripped notes with the spaces around them.
+var noteGapPattern = regexp.MustCompile("[ \t]*(?:\x00[ \t]*)+")
+
+// BookNotes lists the notes in every scene of a book, in reading order.
+func BookNotes(inputFile string) ([]Note, error) {
+	_, book, err := LoadBook(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	var notes []Note
+	for chapter := range book.GetChapters() {
+		if err := chapter.Validate(); err != nil {
+			return nil, err
+		}
+		scenes, err := chapter.LoadScenes()
+		if err != nil {
+			return nil, err
+		}
+		for i := range scenes {
+			notes = append(notes, FindNotes(&scenes[i])...)
+		}
+	}
+	return notes, nil
+}
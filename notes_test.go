@@ -0,0 +1,89 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindNotes(t *testing.T) {
+	scene, err := ReadScene("testdata/scenes/notes.md")
+	require.NoError(t, err)
+
+	notes := FindNotes(scene)
+	require.Len(t, notes, 5)
+	assert.Equal(t, Note{File: "testdata/scenes/notes.md", Line: 4, Kind: NoteComment, Text: "check tide tables"}, notes[0])
+	assert.Equal(t, Note{File: "testdata/scenes/notes.md", Line: 6, Kind: NoteBracket, Text: "Does she know about the letter yet?"}, notes[1])
+	assert.Equal(t, Note{File: "testdata/scenes/notes.md", Line: 8, Kind: NotePlaceholder}, notes[2])
+	assert.Equal(t, Note{File: "testdata/scenes/notes.md", Line: 8, Kind: NoteCritic, Text: "what's in it?"}, notes[3])
+	assert.Equal(t, Note{File: "testdata/scenes/notes.md", Line: 10, Kind: NoteComment, Text: "Cut the storm scene?"}, notes[4])
+
+	assert.Equal(t, "testdata/scenes/notes.md:8: placeholder", notes[2].String())
+	assert.Equal(t, "testdata/scenes/notes.md:6: note: Does she know about the letter yet?", notes[1].String())
+}
+
+func TestStripNotes(t *testing.T) {
+	scene, err := ReadScene("testdata/scenes/notes.md")
+	require.NoError(t, err)
+	assert.Equal(t, "The ship left port at dawn.\n\n"+
+		"It carried passengers and a crate of oranges.\n\n"+
+		"The TKO was a surprise.\n", StripNotes(scene.Body))
+}
+
+func TestStripNotes_Spacing(t *testing.T) {
+	tests := map[string]string{
+		"no notes here":                   "no notes here",
+		"a [[x]] b":                       "a b",
+		"ends [[x]].":                     "ends.",
+		"[[x]] starts":                    "starts",
+		"glued[[x]]word":                  "gluedword",
+		"one\n<!-- a --> <!-- b -->\ntwo": "one\ntwo",
+		"<!-- only -->\n\nText":           "Text",
+		"Use `[[x]]` or `TK` in code.":    "Use `[[x]]` or `TK` in code.",
+		"```\nTK [[x]]\n```\n":            "```\nTK [[x]]\n```\n",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, StripNotes(in), in)
+	}
+}
+
+func TestFindNotes_SkipsCode(t *testing.T) {
+	scene := &Scene{Path: "scene.md", BodyLine: 1, Body: "Use `[[x]]` or `TK` in code.\n\n```\n<!-- kept -->\n```\n\nBut TK here.\n"}
+	assert.Equal(t, []Note{{File: "scene.md", Line: 7, Kind: NotePlaceholder}}, FindNotes(scene))
+}
+
+func TestSceneOptions_Drafts(t *testing.T) {
+	scene := &Scene{Body: "Text [[note]] here."}
+	assert.Equal(t, "Text here.", SceneOptions{}.body(scene))
+	assert.Equal(t, "Text [[note]] here.", SceneOptions{Drafts: true}.body(scene))
+}
+
+func TestBookNotes(t *testing.T) {
+	root := copyManuscript(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "manuscript", "baz.md"), []byte("One TK.\n\nTwo [[fix]].\n"), 0644))
+
+	notes, err := BookNotes(filepath.Join(root, "book.yaml"))
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, 1, notes[0].Line)
+	assert.Equal(t, 3, notes[1].Line)
+	assert.True(t, strings.HasSuffix(notes[1].File, filepath.Join("manuscript", "baz.md")))
+
+	outdir := filepath.Join(root, "out")
+	_, _, err = AssembleMarkdown(AssemblyConfig{InputFile: filepath.Join(root, "book.yaml"), OutputDir: outdir})
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(outdir, "002-chapter-one.md"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "TK")
+	assert.Contains(t, string(content), "Two.")
+
+	_, _, err = AssembleMarkdown(AssemblyConfig{InputFile: filepath.Join(root, "book.yaml"), OutputDir: outdir, Drafts: true})
+	require.NoError(t, err)
+	content, err = os.ReadFile(filepath.Join(outdir, "002-chapter-one.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Two [[fix]].")
+}
@@ -0,0 +1,140 @@
+package binder
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Numbering styles for chapter headings.
+const (
+	NumberWords  = "words"
+	NumberArabic = "arabic"
+	NumberRoman  = "roman"
+	NumberNone   = "none"
+)
+
+// Numbering controls how chapter headings are numbered, read from the
+// "numbering" key of the book spec.
+type Numbering struct {
+	Style string `yaml:"style,omitempty"` // words (default), arabic, roman or none
+	// Template renders the heading of every numbered chapter, e.g.
+	// "{{.Number}}: {{.Name}}". When set, named chapters are numbered too.
+	Template       string `yaml:"template,omitempty"`
+	RestartPerPart bool   `yaml:"restart_per_part,omitempty"`
+	// Label overrides the locale's word for "Chapter".
+	Label string `yaml:"label,omitempty"`
+}
+
+// HeadingData is passed to the numbering template.
+type HeadingData struct {
+	Label  string // the localized word for "Chapter"
+	Number string // formatted chapter number, empty for style "none"
+	Name   string // custom chapter name, title-cased; may be empty
+}
+
+// Validate checks that the numbering style is known and the template parses
+// and renders a sample heading, so that fields HeadingData lacks, such as
+// {{.Title}}, are caught when the book is loaded.
+func (n Numbering) Validate() error {
+	switch n.Style {
+	case "", NumberWords, NumberArabic, NumberRoman, NumberNone:
+	default:
+		return fmt.Errorf("unknown numbering style %q (want words, arabic, roman or none)", n.Style)
+	}
+	tmpl, err := n.template()
+	if err != nil {
+		return fmt.Errorf("invalid numbering template: %w", err)
+	}
+	if tmpl != nil {
+		sample := HeadingData{Label: "Chapter", Number: "One", Name: "Name"}
+		if err := tmpl.Execute(io.Discard, sample); err != nil {
+			return fmt.Errorf("invalid numbering template: %w", err)
+		}
+	}
+	return nil
+}
+
+func (n Numbering) template() (*template.Template, error) {
+	if n.Template == "" {
+		return nil, nil
+	}
+	return template.New("heading").Option("missingkey=error").Parse(n.Template)
+}
+
+// numbersNamed reports whether named chapters consume a chapter number.
+func (n Numbering) numbersNamed() bool {
+	return n.Template != ""
+}
+
+// FormatNumber formats a chapter number in the configured style, spelling
+// out words in the given locale.
+func (n Numbering) FormatNumber(number int, locale Locale) string {
+	switch n.Style {
+	case NumberArabic:
+		return strconv.Itoa(number)
+	case NumberRoman:
+		return RomanNumeral(number)
+	case NumberNone:
+		return ""
+	default:
+		return locale.Spell(number)
+	}
+}
+
+// label returns the word for "Chapter" in the given locale, unless the
+// book overrides it.
+func (n Numbering) label(locale Locale) string {
+	if n.Label != "" {
+		return n.Label
+	}
+	return locale.ChapterLabel
+}
+
+// heading builds a chapter heading from its title-cased name and number
+// using tmpl, the parsed numbering template (nil for the default heading).
+// A number of 0 means the chapter is not numbered. If the template fails to
+// render, the error is returned with the name as the heading.
+func (n Numbering) heading(tmpl *template.Template, name string, number int, locale Locale) (string, error) {
+	if number == 0 {
+		return name, nil
+	}
+	data := HeadingData{Label: n.label(locale), Name: name, Number: n.FormatNumber(number, locale)}
+	if tmpl == nil {
+		if data.Number == "" {
+			return name, nil
+		}
+		return data.Label + " " + data.Number, nil
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return name, fmt.Errorf("numbering template for chapter %d: %w", number, err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+var romanNumerals = []struct {
+	value  int
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+// RomanNumeral formats a positive number as upper-case Roman numerals.
+func RomanNumeral(number int) string {
+	if number <= 0 {
+		return strconv.Itoa(number)
+	}
+	var sb strings.Builder
+	for _, rn := range romanNumerals {
+		for number >= rn.value {
+			sb.WriteString(rn.symbol)
+			number -= rn.value
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,156 @@
+package binder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func collectHeadings(book *Book) []string {
+	var headings []string
+	for ch := range book.GetChapters() {
+		headings = append(headings, ch.Heading)
+	}
+	return headings
+}
+
+func TestNumbering_Styles(t *testing.T) {
+	chapters := []Chapter{
+		{Scenes: []string{"a"}},
+		{Name: "Interval", Interlude: true, Scenes: []string{"b"}},
+		{Scenes: []string{"c"}},
+		{Scenes: []string{"d"}},
+		{Scenes: []string{"e"}},
+	}
+
+	cases := []struct {
+		style    string
+		expected []string
+	}{
+		{"", []string{"Chapter One", "Interval", "Chapter Two", "Chapter Three", "Chapter Four"}},
+		{NumberWords, []string{"Chapter One", "Interval", "Chapter Two", "Chapter Three", "Chapter Four"}},
+		{NumberArabic, []string{"Chapter 1", "Interval", "Chapter 2", "Chapter 3", "Chapter 4"}},
+		{NumberRoman, []string{"Chapter I", "Interval", "Chapter II", "Chapter III", "Chapter IV"}},
+		{NumberNone, []string{"", "Interval", "", "", ""}},
+	}
+	for _, c := range cases {
+		book := &Book{BaseDir: "m", Chapters: chapters, Numbering: Numbering{Style: c.style}}
+		assert.Equal(t, c.expected, collectHeadings(book), "style %q", c.style)
+	}
+}
+
+func TestNumbering_NoneStillNumbersChapters(t *testing.T) {
+	book := &Book{
+		BaseDir:   "m",
+		Chapters:  []Chapter{{Scenes: []string{"a"}}, {Scenes: []string{"b"}}},
+		Numbering: Numbering{Style: NumberNone},
+	}
+
+	var chapters []IteratedChapter
+	for ch := range book.GetChapters() {
+		chapters = append(chapters, ch)
+	}
+
+	require.Len(t, chapters, 2)
+	assert.Equal(t, 2, chapters[1].Number)
+	assert.Equal(t, "chapter-2", chapters[1].HeadingToFilename())
+}
+
+func TestNumbering_TemplateNumbersNamedChapters(t *testing.T) {
+	book := &Book{
+		BaseDir: "m",
+		Chapters: []Chapter{
+			{Name: "the arrival", Scenes: []string{"a"}},
+			{Scenes: []string{"b"}},
+			{Interlude: true, Scenes: []string{"c"}},
+			{Name: "the departure", Scenes: []string{"d"}},
+		},
+		Numbering: Numbering{
+			Style:    NumberArabic,
+			Template: "{{.Number}}{{if .Name}}: {{.Name}}{{end}}",
+		},
+	}
+
+	assert.Equal(t, []string{"1: The Arrival", "2", "", "3: The Departure"}, collectHeadings(book))
+}
+
+func TestNumbering_RestartPerPart(t *testing.T) {
+	book := &Book{
+		BaseDir:  "m",
+		Chapters: []Chapter{{Name: "Prologue", Scenes: []string{"p"}}},
+		Parts: []Part{
+			{Name: "Part One", Chapters: []Chapter{{Scenes: []string{"a"}}, {Scenes: []string{"b"}}}},
+			{Name: "Part Two", Chapters: []Chapter{{Scenes: []string{"c"}}}},
+		},
+		Numbering: Numbering{RestartPerPart: true},
+	}
+	assert.Equal(t, []string{"Prologue", "Chapter One", "Chapter Two", "Chapter One"}, collectHeadings(book))
+
+	book.Numbering.RestartPerPart = false
+	assert.Equal(t, []string{"Prologue", "Chapter One", "Chapter Two", "Chapter Three"}, collectHeadings(book))
+}
+
+func TestNumbering_Validate(t *testing.T) {
+	assert.NoError(t, Numbering{}.Validate())
+	assert.NoError(t, Numbering{Style: NumberRoman, Template: "{{.Number}}. {{.Name}}"}.Validate())
+
+	err := Numbering{Style: "klingon"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown numbering style")
+
+	err = Numbering{Template: "{{.Number"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid numbering template")
+
+	err = Numbering{Template: "{{.Title}}"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't evaluate field Title")
+}
+
+func TestNumbering_HeadingRenderError(t *testing.T) {
+	tmpl, err := Numbering{Template: "{{index .Name 5}}"}.template()
+	require.NoError(t, err)
+	heading, err := Numbering{}.heading(tmpl, "Dawn", 1, Locale{})
+	assert.Error(t, err)
+	assert.Equal(t, "Dawn", heading)
+
+	chapter := IteratedChapter{headingErr: err}
+	assert.Equal(t, err, chapter.Validate())
+}
+
+func TestNumbering_Unmarshal(t *testing.T) {
+	yamlData := `
+base_dir: manuscript
+numbering:
+  style: roman
+  template: "{{.Number}}: {{.Name}}"
+  restart_per_part: true
+`
+	var book Book
+	err := yaml.Unmarshal([]byte(yamlData), &book)
+	require.NoError(t, err)
+
+	assert.Equal(t, NumberRoman, book.Numbering.Style)
+	assert.Equal(t, "{{.Number}}: {{.Name}}", book.Numbering.Template)
+	assert.True(t, book.Numbering.RestartPerPart)
+}
+
+func TestLoadBook_InvalidNumbering(t *testing.T) {
+	fm, book, err := LoadBook("testdata/invalid_numbering.yaml")
+	require.Error(t, err)
+	assert.Nil(t, fm)
+	assert.Nil(t, book)
+	assert.Contains(t, err.Error(), "unknown numbering style")
+}
+
+func TestRomanNumeral(t *testing.T) {
+	assert.Equal(t, "I", RomanNumeral(1))
+	assert.Equal(t, "IV", RomanNumeral(4))
+	assert.Equal(t, "IX", RomanNumeral(9))
+	assert.Equal(t, "XIV", RomanNumeral(14))
+	assert.Equal(t, "XLII", RomanNumeral(42))
+	assert.Equal(t, "MCMXCIX", RomanNumeral(1999))
+	assert.Equal(t, "0", RomanNumeral(0))
+}
@@ -0,0 +1,371 @@
+package binder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestFile marks a directory as binder output. It lists the files the
+// last assembly generated, with hashes of what each was generated from, so
+// the next one can skip unchanged files and remove exactly those that are
+// no longer produced.
+const ManifestFile = ".binder-manifest.json"
+
+// manifestVersion is mixed into every input hash; bump it whenever the
+// generated output changes for the same inputs.
+const manifestVersion = 1
+
+// ErrUnmanagedOutputDir is returned when the output directory already holds
+// files but was not created by binder.
+var ErrUnmanagedOutputDir = errors.New("output directory is not empty and was not created by binder")
+
+type outputManifest struct {
+	Generator string         `json:"generator"`
+	Files     []manifestFile `json:"files"`
+}
+
+// manifestFile describes one generated file.
+type manifestFile struct {
+	Name string `json:"name"`
+	// Inputs hashes everything the file was generated from: headings,
+	// options and the content hashes below.
+	Inputs string            `json:"inputs"`
+	Spec   string            `json:"spec,omitempty"`   // content hash of the book spec
+	Scenes map[string]string `json:"scenes,omitempty"` // scene path to content hash
+}
+
+// outputDir is an output directory being assembled into. Files are
+// written to a staging directory beside it and only published by commit,
+// so a failed assembly leaves the previous output untouched.
+type outputDir struct {
+	path     string
+	staging  string
+	previous map[string]string // files generated by the last assembly, by input hash
+	files    []manifestFile
+	reused   map[string]bool
+}
+
+// prepareOutputDir readies dir for a fresh assembly and creates its staging
+// directory. A non-empty directory without a manifest is refused unless
+// force is set, and a directory containing the book spec or its manuscript
+// is always refused.
+func prepareOutputDir(dir, inputFile, baseDir string, force bool) (*outputDir, error) {
+	for _, protected := range []string{inputFile, baseDir} {
+		if protected == "" {
+			continue
+		}
+		inside, err := pathWithin(protected, dir)
+		if err != nil {
+			return nil, err
+		}
+		if inside {
+			return nil, fmt.Errorf("refusing to assemble into %s: it contains %s", dir, protected)
+		}
+	}
+	od := &outputDir{previous: map[string]string{}, reused: map[string]bool{}}
+	var err error
+	if od.path, err = filepath.Abs(dir); err != nil {
+		return nil, err
+	}
+	manifest, err := readManifest(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if len(entries) > 0 && !force {
+			return nil, fmt.Errorf("%s: %w", dir, ErrUnmanagedOutputDir)
+		}
+	case err != nil:
+		return nil, err
+	default:
+		for _, file := range manifest.Files {
+			// Only plain names are honoured so a tampered manifest cannot
+			// reach outside the directory.
+			if file.Name == filepath.Base(file.Name) && file.Name != "." && file.Name != ".." {
+				od.previous[file.Name] = file.Inputs
+			}
+		}
+	}
+	parent := filepath.Dir(od.path)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, err
+	}
+	if od.staging, err = os.MkdirTemp(parent, "."+filepath.Base(od.path)+".staging-*"); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(od.staging, 0755); err != nil {
+		od.abort()
+		return nil, err
+	}
+	return od, nil
+}
+
+// file returns the staging path of a generated file and records it in the
+// manifest.
+func (od *outputDir) file(entry manifestFile) string {
+	od.files = append(od.files, entry)
+	return filepath.Join(od.staging, entry.Name)
+}
+
+// reuse carries a file over from the previous output when it was generated
+// from the same inputs, linking it into the staging directory rather than
+// writing it again. It reports whether the file was reused.
+func (od *outputDir) reuse(entry manifestFile) (bool, error) {
+	if inputs, ok := od.previous[entry.Name]; !ok || inputs != entry.Inputs {
+		return false, nil
+	}
+	existing := filepath.Join(od.path, entry.Name)
+	if _, err := os.Stat(existing); err != nil {
+		return false, nil
+	}
+	staged := od.file(entry)
+	if err := os.Link(existing, staged); err != nil {
+		if err := copyFile(existing, staged); err != nil {
+			return false, err
+		}
+	}
+	od.reused[entry.Name] = true
+	return true, nil
+}
+
+// abort discards the staging directory. It is a no-op after commit.
+func (od *outputDir) abort() {
+	_ = os.RemoveAll(od.staging)
+}
+
+// commit writes the manifest and publishes the staged files. When the
+// output directory holds nothing but binder output, the staging directory
+// is renamed into its place. Otherwise each file is moved in individually,
+// leaving files binder did not generate alone, and stale output from the
+// previous assembly is removed.
+func (od *outputDir) commit() error {
+	if err := od.writeManifest(); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(od.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.Rename(od.staging, od.path)
+	}
+	if err != nil {
+		return err
+	}
+	owned := map[string]bool{ManifestFile: true}
+	for name := range od.previous {
+		owned[name] = true
+	}
+	foreign := false
+	for _, entry := range entries {
+		if !owned[entry.Name()] {
+			foreign = true
+			break
+		}
+	}
+	if !foreign {
+		return od.swap()
+	}
+
+	current := map[string]bool{}
+	for _, file := range od.files {
+		current[file.Name] = true
+		// Reused files are already in place; renaming a hard link over
+		// itself would leave the staged link behind.
+		if od.reused[file.Name] {
+			continue
+		}
+		if err := os.Rename(filepath.Join(od.staging, file.Name), filepath.Join(od.path, file.Name)); err != nil {
+			return err
+		}
+	}
+	for name := range od.previous {
+		if current[name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(od.path, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	// The manifest goes last: until it is replaced, the previous one still
+	// covers every file a retry would need to clean up.
+	if err := os.Rename(filepath.Join(od.staging, ManifestFile), filepath.Join(od.path, ManifestFile)); err != nil {
+		return err
+	}
+	return os.RemoveAll(od.staging)
+}
+
+// swap replaces the output directory with the staging directory, moving
+// the old output aside first and restoring it if the swap fails.
+func (od *outputDir) swap() error {
+	old, err := os.MkdirTemp(filepath.Dir(od.path), "."+filepath.Base(od.path)+".old-*")
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(old); err != nil {
+		return err
+	}
+	if err := os.Rename(od.path, old); err != nil {
+		return err
+	}
+	if err := os.Rename(od.staging, od.path); err != nil {
+		_ = os.Rename(old, od.path)
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+// writeManifest records the generated files in the staging directory,
+// marking it as binder output.
+func (od *outputDir) writeManifest() error {
+	contents, err := json.MarshalIndent(outputManifest{Generator: "binder", Files: od.files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(od.staging, ManifestFile), append(contents, '\n'), 0644)
+}
+
+// writeFileAtomic writes a single output file by way of a temporary file in
+// the same directory, renamed over path only once write has succeeded.
+func writeFileAtomic(path string, write func(fd *os.File) error) error {
+	fd, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := fd.Name()
+	if err := write(fd); err != nil {
+		fd.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := fd.Sync(); err != nil {
+		fd.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeBytesAtomic is writeFileAtomic for content already held in memory.
+func writeBytesAtomic(path string, data []byte) error {
+	return writeFileAtomic(path, func(fd *os.File) error {
+		_, err := fd.Write(data)
+		return err
+	})
+}
+
+func readManifest(dir string) (*outputManifest, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &outputManifest{}
+	if err := json.Unmarshal(contents, manifest); err != nil {
+		return nil, fmt.Errorf("%s: invalid manifest: %w", filepath.Join(dir, ManifestFile), err)
+	}
+	return manifest, nil
+}
+
+// pathWithin reports whether path is dir or lies beneath it.
+func pathWithin(path, dir string) (bool, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return false, nil
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))), nil
+}
+
+// chapterManifestEntry describes the chapter file name generated from
+// chapter, hashing its heading, options and scene contents.
+func chapterManifestEntry(name string, chapter IteratedChapter, options SceneOptions) (manifestFile, error) {
+	entry := manifestFile{Name: name, Scenes: map[string]string{}}
+	h := sha256.New()
+	fmt.Fprintf(h, "binder %d\nchapter %q\noptions %+v\n", manifestVersion, chapter.Heading, options)
+	for _, scene := range chapter.Scenes {
+		sum, err := hashFile(scene)
+		if err != nil {
+			return manifestFile{}, err
+		}
+		entry.Scenes[scene] = sum
+		fmt.Fprintf(h, "scene %q %s\n", scene, sum)
+	}
+	entry.Inputs = hex.EncodeToString(h.Sum(nil))
+	return entry, nil
+}
+
+// partManifestEntry describes the part-title file name generated from part.
+func partManifestEntry(name string, part *IteratedPart) manifestFile {
+	h := sha256.New()
+	fmt.Fprintf(h, "binder %d\npart %q\n%q\n", manifestVersion, part.Heading, part.Text)
+	return manifestFile{Name: name, Inputs: hex.EncodeToString(h.Sum(nil))}
+}
+
+// specManifestEntry describes the file name generated from the book spec
+// alone, such as metadata.yaml.
+func specManifestEntry(name, specFile string) (manifestFile, error) {
+	sum, err := hashFile(specFile)
+	if err != nil {
+		return manifestFile{}, err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "binder %d\nspec %s\n", manifestVersion, sum)
+	return manifestFile{Name: name, Inputs: hex.EncodeToString(h.Sum(nil)), Spec: sum}, nil
+}
+
+// hashFile returns the hex-encoded SHA-256 of a file's contents.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// copyFile copies src to dst, for when dst cannot be a hard link to src.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,304 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssembleMarkdown_CreatesOutputDir(t *testing.T) {
+	outdir := filepath.Join(t.TempDir(), "nested", "out")
+
+	_, _, err := AssembleMarkdown(AssemblyConfig{InputFile: "testdata/valid_book.yaml", OutputDir: outdir})
+	require.NoError(t, err)
+
+	manifest, err := readManifest(outdir)
+	require.NoError(t, err)
+	assert.Equal(t, "binder", manifest.Generator)
+	var names []string
+	for _, file := range manifest.Files {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{
+		"001-interlude.md",
+		"002-chapter-one.md",
+		"003-interlude.md",
+		"004-chapter-two.md",
+		"metadata.yaml",
+	}, names)
+}
+
+func TestAssembleMarkdown_RefusesUnmanagedDir(t *testing.T) {
+	outdir := t.TempDir()
+	keep := filepath.Join(outdir, "notes.txt")
+	require.NoError(t, os.WriteFile(keep, []byte("precious"), 0644))
+
+	_, _, err := AssembleMarkdown(AssemblyConfig{InputFile: "testdata/valid_book.yaml", OutputDir: outdir})
+	require.ErrorIs(t, err, ErrUnmanagedOutputDir)
+
+	entries, err := os.ReadDir(outdir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "nothing should be written to a refused directory")
+}
+
+func TestAssembleMarkdown_ForceKeepsForeignFiles(t *testing.T) {
+	outdir := t.TempDir()
+	keep := filepath.Join(outdir, "notes.txt")
+	require.NoError(t, os.WriteFile(keep, []byte("precious"), 0644))
+
+	config := AssemblyConfig{InputFile: "testdata/valid_book.yaml", OutputDir: outdir, Force: true}
+	_, _, err := AssembleMarkdown(config)
+	require.NoError(t, err)
+
+	// Once marked, later runs need no force and still leave foreign files alone
+	config.Force = false
+	_, _, err = AssembleMarkdown(config)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(keep)
+	require.NoError(t, err)
+	assert.Equal(t, "precious", string(content))
+	assert.FileExists(t, filepath.Join(outdir, "002-chapter-one.md"))
+}
+
+func TestAssembleMarkdown_RemovesStaleOutput(t *testing.T) {
+	outdir := t.TempDir()
+
+	_, _, err := AssembleMarkdown(AssemblyConfig{InputFile: "testdata/valid_book.yaml", OutputDir: outdir})
+	require.NoError(t, err)
+	_, _, err = AssembleMarkdown(AssemblyConfig{InputFile: "testdata/book_with_matter.yaml", OutputDir: outdir})
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, filepath.Join(outdir, "001-interlude.md"))
+	files, err := OutputFiles(outdir)
+	require.NoError(t, err)
+	assert.Len(t, files, 6)
+}
+
+func TestAssembleMarkdown_RefusesDirContainingInput(t *testing.T) {
+	for _, outdir := range []string{"testdata", "testdata/manuscript", "."} {
+		_, _, err := AssembleMarkdown(AssemblyConfig{InputFile: "testdata/valid_book.yaml", OutputDir: outdir, Force: true})
+		require.Error(t, err, outdir)
+		assert.Contains(t, err.Error(), "refusing to assemble into")
+	}
+	assert.FileExists(t, "testdata/manuscript/foo.md")
+}
+
+func TestPrepareOutputDir_IgnoresPathsInManifest(t *testing.T) {
+	root := t.TempDir()
+	outdir := filepath.Join(root, "out")
+	require.NoError(t, os.Mkdir(outdir, 0755))
+	outside := filepath.Join(root, "outside.md")
+	require.NoError(t, os.WriteFile(outside, []byte("x"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outdir, ManifestFile),
+		[]byte(`{"generator":"binder","files":[{"name":"../outside.md"}]}`), 0644))
+
+	_, err := prepareOutputDir(outdir, "book.yaml", "manuscript", false)
+	require.NoError(t, err)
+	assert.FileExists(t, outside)
+}
+
+func TestPathWithin(t *testing.T) {
+	inside, err := pathWithin("a/b/c.yaml", "a")
+	require.NoError(t, err)
+	assert.True(t, inside)
+
+	inside, err = pathWithin("a", "a")
+	require.NoError(t, err)
+	assert.True(t, inside)
+
+	inside, err = pathWithin("ab/c.yaml", "a")
+	require.NoError(t, err)
+	assert.False(t, inside)
+
+	inside, err = pathWithin("..foo/c.yaml", ".")
+	require.NoError(t, err)
+	assert.True(t, inside)
+}
+
+func TestAssembleMarkdown_FailureKeepsPreviousOutput(t *testing.T) {
+	root := t.TempDir()
+	outdir := filepath.Join(root, "out")
+	_, _, err := AssembleMarkdown(AssemblyConfig{InputFile: "testdata/valid_book.yaml", OutputDir: outdir})
+	require.NoError(t, err)
+	before, err := os.ReadFile(filepath.Join(outdir, "002-chapter-one.md"))
+	require.NoError(t, err)
+
+	// The second chapter's scene is missing, so assembly fails part way
+	spec := filepath.Join(root, "broken.yaml")
+	manuscript, err := filepath.Abs("testdata/manuscript")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(spec, []byte("---\ntitle: Broken\n---\nbook:\n  base_dir: "+manuscript+
+		"\n  chapters:\n    - scenes: [\"foo\"]\n    - scenes: [\"missing\"]\n"), 0644))
+	_, _, err = AssembleMarkdown(AssemblyConfig{InputFile: spec, OutputDir: outdir})
+	require.Error(t, err)
+
+	after, err := os.ReadFile(filepath.Join(outdir, "002-chapter-one.md"))
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+	files, err := OutputFiles(outdir)
+	require.NoError(t, err)
+	assert.Len(t, files, 4)
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"out", "broken.yaml"}, names, "the staging directory should be removed")
+}
+
+func TestAssembleMarkdown_NoStagingLeftBehind(t *testing.T) {
+	root := t.TempDir()
+	outdir := filepath.Join(root, "out")
+	for range 2 {
+		_, _, err := AssembleMarkdown(AssemblyConfig{InputFile: "testdata/valid_book.yaml", OutputDir: outdir})
+		require.NoError(t, err)
+	}
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "out", entries[0].Name())
+}
+
+func TestWriteFileAtomic_FailureKeepsExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "book.html")
+	require.NoError(t, writeBytesAtomic(path, []byte("first")))
+
+	err := writeFileAtomic(path, func(fd *os.File) error {
+		_, _ = fd.WriteString("partial")
+		return assert.AnError
+	})
+	require.ErrorIs(t, err, assert.AnError)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file should be removed")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestAssembleMarkdown_ManifestHashes(t *testing.T) {
+	outdir := t.TempDir()
+	_, _, err := AssembleMarkdown(AssemblyConfig{InputFile: "testdata/valid_book.yaml", OutputDir: outdir})
+	require.NoError(t, err)
+
+	manifest, err := readManifest(outdir)
+	require.NoError(t, err)
+	fooHash, err := hashFile("testdata/manuscript/foo.md")
+	require.NoError(t, err)
+	specHash, err := hashFile("testdata/valid_book.yaml")
+	require.NoError(t, err)
+
+	chapterOne := manifest.Files[1]
+	assert.Equal(t, "002-chapter-one.md", chapterOne.Name)
+	assert.Equal(t, fooHash, chapterOne.Scenes["testdata/manuscript/foo.md"])
+	assert.Len(t, chapterOne.Scenes, 2)
+	assert.NotEmpty(t, chapterOne.Inputs)
+
+	metadata := manifest.Files[len(manifest.Files)-1]
+	assert.Equal(t, "metadata.yaml", metadata.Name)
+	assert.Equal(t, specHash, metadata.Spec)
+}
+
+// copyManuscript copies the valid test book and its scenes into a temporary
+// directory so tests can edit them.
+func copyManuscript(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "manuscript"), 0755))
+	for _, name := range []string{"interlude1", "foo", "baz", "interlude2", "bar", "quux"} {
+		require.NoError(t, copyFile(filepath.Join("testdata", "manuscript", name+".md"), filepath.Join(root, "manuscript", name+".md")))
+	}
+	require.NoError(t, copyFile("testdata/valid_book.yaml", filepath.Join(root, "book.yaml")))
+	return root
+}
+
+func TestAssembleMarkdown_Incremental(t *testing.T) {
+	root := copyManuscript(t)
+	outdir := filepath.Join(root, "out")
+	config := AssemblyConfig{InputFile: filepath.Join(root, "book.yaml"), OutputDir: outdir}
+	_, _, err := AssembleMarkdown(config)
+	require.NoError(t, err)
+
+	stat := func(name string) os.FileInfo {
+		info, err := os.Stat(filepath.Join(outdir, name))
+		require.NoError(t, err)
+		return info
+	}
+	interlude := stat("001-interlude.md")
+	chapterTwo := stat("004-chapter-two.md")
+	metadata := stat("metadata.yaml")
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "manuscript", "bar.md"), []byte("This is a new bar."), 0644))
+	_, _, err = AssembleMarkdown(config)
+	require.NoError(t, err)
+
+	assert.True(t, os.SameFile(interlude, stat("001-interlude.md")), "unchanged chapters should be carried over")
+	assert.True(t, os.SameFile(metadata, stat("metadata.yaml")), "metadata should be carried over while the spec is unchanged")
+	assert.False(t, os.SameFile(chapterTwo, stat("004-chapter-two.md")), "chapters with edited scenes should be rewritten")
+
+	content, err := os.ReadFile(filepath.Join(outdir, "004-chapter-two.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "This is a new bar.")
+}
+
+func TestAssembleMarkdown_IncrementalOptionsAndRemovals(t *testing.T) {
+	root := copyManuscript(t)
+	outdir := filepath.Join(root, "out")
+	spec := filepath.Join(root, "book.yaml")
+	config := AssemblyConfig{InputFile: spec, OutputDir: outdir}
+	_, _, err := AssembleMarkdown(config)
+	require.NoError(t, err)
+
+	// Scene headings change every chapter
+	config.SceneHeadings = true
+	_, _, err = AssembleMarkdown(config)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(outdir, "002-chapter-one.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "## foo")
+
+	// Dropping the last two chapters removes their files
+	require.NoError(t, os.WriteFile(spec, []byte("---\ntitle: Shorter\n---\nbook:\n  base_dir: manuscript\n  chapters:\n    - interlude: true\n      scenes: [interlude1]\n    - scenes: [foo, baz]\n"), 0644))
+	_, _, err = AssembleMarkdown(config)
+	require.NoError(t, err)
+	files, err := OutputFiles(outdir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	metadata, err := os.ReadFile(filepath.Join(outdir, "metadata.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), "title: Shorter")
+}
+
+func TestAssembleMarkdown_IncrementalWithForeignFiles(t *testing.T) {
+	root := copyManuscript(t)
+	outdir := filepath.Join(root, "out")
+	require.NoError(t, os.Mkdir(outdir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(outdir, "notes.txt"), []byte("keep"), 0644))
+	config := AssemblyConfig{InputFile: filepath.Join(root, "book.yaml"), OutputDir: outdir, Force: true}
+	_, _, err := AssembleMarkdown(config)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "manuscript", "foo.md"), []byte("This is a new foo."), 0644))
+	_, _, err = AssembleMarkdown(config)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outdir, "002-chapter-one.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "This is a new foo.")
+	assert.FileExists(t, filepath.Join(outdir, "notes.txt"))
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "no staging directory should be left behind")
+}
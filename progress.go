@@ -0,0 +1,272 @@
+package binder
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ProgressSnapshot is one entry of a book's progress history: the word
+// count of every scene at a moment in time.
+type ProgressSnapshot struct {
+	Time  time.Time `json:"time"`
+	Words int       `json:"words"`
+	// Scenes maps scene paths, relative to the book spec, to word counts.
+	Scenes map[string]int `json:"scenes"`
+}
+
+// ProgressFile returns the history file kept next to a book spec, e.g.
+// "novel.progress.jsonl" for "novel.yaml".
+func ProgressFile(inputFile string) string {
+	base := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
+	return filepath.Join(filepath.Dir(inputFile), base+".progress.jsonl")
+}
+
+// RecordProgress counts the words of every scene in the book and appends
+// the snapshot, taken at now, to the book's history file.
+func RecordProgress(inputFile string, now time.Time) (*ProgressSnapshot, error) {
+	stats, err := CollectStats(inputFile, "")
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &ProgressSnapshot{Time: now.Truncate(time.Second), Words: stats.Words, Scenes: map[string]int{}}
+	specDir := filepath.Dir(inputFile)
+	for _, chapter := range stats.Chapters {
+		for _, scene := range chapter.Scenes {
+			path, err := filepath.Rel(specDir, scene.Path)
+			if err != nil {
+				path = scene.Path
+			}
+			snapshot.Scenes[filepath.ToSlash(path)] = scene.Count
+		}
+	}
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := os.OpenFile(ProgressFile(inputFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fd.Write(append(line, '\n')); err != nil {
+		fd.Close()
+		return nil, err
+	}
+	if err := fd.Close(); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// LoadProgress reads a book's history file in the order it was recorded.
+// A book without history has no snapshots.
+func LoadProgress(inputFile string) ([]ProgressSnapshot, error) {
+	path := ProgressFile(inputFile)
+	fd, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	var snapshots []ProgressSnapshot
+	scanner := bufio.NewScanner(fd)
+	scanner.Buffer(nil, 16*1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var snapshot ProgressSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, scanner.Err()
+}
+
+// DailyProgress is the net number of words written on one day.
+type DailyProgress struct {
+	Date  string // YYYY-MM-DD in the snapshot's time zone
+	Words int    // may be negative on days spent cutting
+	Total int    // book total at the day's last snapshot
+}
+
+// WeeklyProgress is the net number of words written in one ISO week.
+type WeeklyProgress struct {
+	Week  string // e.g. "2026-W42"
+	Words int
+}
+
+// SceneChange is a scene whose word count differs between two snapshots.
+type SceneChange struct {
+	Scene   string
+	Before  int
+	After   int
+	Added   bool // the scene is new to the book
+	Removed bool // the scene is no longer in the book
+}
+
+// ProgressReport summarizes a progress history.
+type ProgressReport struct {
+	Days  []DailyProgress
+	Weeks []WeeklyProgress
+	// CurrentStreak counts consecutive days with words written, ending
+	// today or, if nothing has been written yet today, yesterday.
+	CurrentStreak int
+	LongestStreak int
+	// Changes lists the scenes that changed between the last two
+	// snapshots, which were taken at Since and Until.
+	Changes []SceneChange
+	Since   time.Time
+	Until   time.Time
+}
+
+// BuildProgressReport works out daily and weekly word counts, streaks and
+// the latest scene changes from a history. The first snapshot is the
+// baseline, so words counted before it are not credited to any day.
+func BuildProgressReport(snapshots []ProgressSnapshot, now time.Time) ProgressReport {
+	var report ProgressReport
+	if len(snapshots) == 0 {
+		return report
+	}
+	previousTotal := snapshots[0].Words
+	for _, snapshot := range snapshots {
+		date := snapshot.Time.Format(time.DateOnly)
+		if n := len(report.Days); n > 0 && report.Days[n-1].Date == date {
+			report.Days[n-1].Words += snapshot.Words - report.Days[n-1].Total
+			report.Days[n-1].Total = snapshot.Words
+			continue
+		}
+		if n := len(report.Days); n > 0 {
+			previousTotal = report.Days[n-1].Total
+		}
+		report.Days = append(report.Days, DailyProgress{Date: date, Words: snapshot.Words - previousTotal, Total: snapshot.Words})
+	}
+
+	for _, day := range report.Days {
+		t, _ := time.Parse(time.DateOnly, day.Date)
+		year, week := t.ISOWeek()
+		label := fmt.Sprintf("%d-W%02d", year, week)
+		if n := len(report.Weeks); n > 0 && report.Weeks[n-1].Week == label {
+			report.Weeks[n-1].Words += day.Words
+			continue
+		}
+		report.Weeks = append(report.Weeks, WeeklyProgress{Week: label, Words: day.Words})
+	}
+
+	// Streaks run over consecutive calendar days with words written.
+	streak := 0
+	var last time.Time
+	for _, day := range report.Days {
+		t, _ := time.Parse(time.DateOnly, day.Date)
+		switch {
+		case day.Words <= 0:
+			streak = 0
+		case streak > 0 && t.Sub(last) == 24*time.Hour:
+			streak++
+		default:
+			streak = 1
+		}
+		last = t
+		report.LongestStreak = max(report.LongestStreak, streak)
+	}
+	today, _ := time.Parse(time.DateOnly, now.Format(time.DateOnly))
+	if streak > 0 && today.Sub(last) <= 24*time.Hour {
+		report.CurrentStreak = streak
+	}
+
+	if len(snapshots) > 1 {
+		before, after := snapshots[len(snapshots)-2], snapshots[len(snapshots)-1]
+		report.Since, report.Until = before.Time, after.Time
+		report.Changes = sceneChanges(before.Scenes, after.Scenes)
+	}
+	return report
+}
+
+func sceneChanges(before, after map[string]int) []SceneChange {
+	var changes []SceneChange
+	for scene, words := range after {
+		if previous, ok := before[scene]; !ok || previous != words {
+			changes = append(changes, SceneChange{Scene: scene, Before: previous, After: words, Added: !ok})
+		}
+	}
+	for scene, words := range before {
+		if _, ok := after[scene]; !ok {
+			changes = append(changes, SceneChange{Scene: scene, Before: words, Removed: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Scene < changes[j].Scene })
+	return changes
+}
+
+// Write writes the report's last days days as text, followed by the weekly
+// totals, streaks and the latest scene changes. days <= 0 shows every day.
+func (r ProgressReport) Write(w io.Writer, days int) error {
+	if len(r.Days) == 0 {
+		_, err := fmt.Fprintln(w, "No progress recorded yet.")
+		return err
+	}
+	shown := r.Days
+	if days > 0 && len(shown) > days {
+		shown = shown[len(shown)-days:]
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Day\tWritten\tTotal")
+	for _, day := range shown {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", day.Date, formatDelta(day.Words), FormatThousands(day.Total))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Week\tWritten")
+	for _, week := range r.Weeks {
+		fmt.Fprintf(tw, "%s\t%s\n", week.Week, formatDelta(week.Words))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nCurrent streak: %s\nLongest streak: %s\n", pluralDays(r.CurrentStreak), pluralDays(r.LongestStreak))
+	if r.Until.IsZero() {
+		return nil
+	}
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintf(w, "\nNo scenes changed since %s.\n", r.Since.Format("2006-01-02 15:04"))
+		return err
+	}
+	fmt.Fprintf(w, "\nScenes changed since %s:\n", r.Since.Format("2006-01-02 15:04"))
+	for _, change := range r.Changes {
+		note := formatDelta(change.After - change.Before)
+		switch {
+		case change.Added:
+			note += ", new"
+		case change.Removed:
+			note += ", removed"
+		}
+		if _, err := fmt.Fprintf(w, "  %s: %s words (%s)\n", change.Scene, FormatThousands(change.After), note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatDelta(n int) string {
+	if n > 0 {
+		return "+" + FormatThousands(n)
+	}
+	return FormatThousands(n)
+}
+
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
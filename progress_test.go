@@ -0,0 +1,136 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressFile(t *testing.T) {
+	assert.Equal(t, filepath.Join("books", "novel.progress.jsonl"), ProgressFile("books/novel.yaml"))
+}
+
+func TestRecordProgress(t *testing.T) {
+	root := copyManuscript(t)
+	spec := filepath.Join(root, "book.yaml")
+	first := time.Date(2026, 10, 1, 21, 30, 0, 0, time.UTC)
+
+	snapshot, err := RecordProgress(spec, first)
+	require.NoError(t, err)
+	assert.Equal(t, 20, snapshot.Words)
+	assert.Equal(t, 3, snapshot.Scenes["manuscript/foo.md"])
+	assert.Len(t, snapshot.Scenes, 6)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "manuscript", "foo.md"), []byte("This is a longer foo."), 0644))
+	_, err = RecordProgress(spec, first.Add(24*time.Hour))
+	require.NoError(t, err)
+
+	snapshots, err := LoadProgress(spec)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.True(t, first.Equal(snapshots[0].Time))
+	assert.Equal(t, 22, snapshots[1].Words)
+	assert.Equal(t, 5, snapshots[1].Scenes["manuscript/foo.md"])
+}
+
+func TestLoadProgress_NoHistory(t *testing.T) {
+	snapshots, err := LoadProgress("testdata/valid_book.yaml")
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+}
+
+func TestLoadProgress_InvalidLine(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "book.yaml")
+	require.NoError(t, os.WriteFile(ProgressFile(spec), []byte("{\"words\": 1}\nnot json\n"), 0644))
+	_, err := LoadProgress(spec)
+	assert.ErrorContains(t, err, "book.progress.jsonl:2")
+}
+
+func snapshotAt(day int, hour int, scenes map[string]int) ProgressSnapshot {
+	total := 0
+	for _, words := range scenes {
+		total += words
+	}
+	return ProgressSnapshot{Time: time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC), Words: total, Scenes: scenes}
+}
+
+func TestBuildProgressReport(t *testing.T) {
+	snapshots := []ProgressSnapshot{
+		snapshotAt(5, 9, map[string]int{"a.md": 1000}),
+		snapshotAt(5, 22, map[string]int{"a.md": 1500}),
+		snapshotAt(6, 22, map[string]int{"a.md": 1500, "b.md": 700}),
+		snapshotAt(7, 22, map[string]int{"a.md": 1400, "b.md": 700}),
+		snapshotAt(12, 22, map[string]int{"a.md": 1400, "b.md": 900}),
+		snapshotAt(13, 22, map[string]int{"b.md": 1000, "c.md": 300}),
+	}
+	report := BuildProgressReport(snapshots, time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, []DailyProgress{
+		{Date: "2026-10-05", Words: 500, Total: 1500},
+		{Date: "2026-10-06", Words: 700, Total: 2200},
+		{Date: "2026-10-07", Words: -100, Total: 2100},
+		{Date: "2026-10-12", Words: 200, Total: 2300},
+		{Date: "2026-10-13", Words: -1000, Total: 1300},
+	}, report.Days)
+	assert.Equal(t, []WeeklyProgress{
+		{Week: "2026-W41", Words: 1100},
+		{Week: "2026-W42", Words: -800},
+	}, report.Weeks)
+	assert.Equal(t, 2, report.LongestStreak)
+	assert.Equal(t, 0, report.CurrentStreak)
+	assert.Equal(t, []SceneChange{
+		{Scene: "a.md", Before: 1400, Removed: true},
+		{Scene: "b.md", Before: 900, After: 1000},
+		{Scene: "c.md", After: 300, Added: true},
+	}, report.Changes)
+}
+
+func TestBuildProgressReport_CurrentStreak(t *testing.T) {
+	snapshots := []ProgressSnapshot{
+		snapshotAt(10, 9, map[string]int{"a.md": 100}),
+		snapshotAt(10, 22, map[string]int{"a.md": 200}),
+		snapshotAt(11, 22, map[string]int{"a.md": 300}),
+		snapshotAt(12, 22, map[string]int{"a.md": 400}),
+	}
+	report := BuildProgressReport(snapshots, time.Date(2026, 10, 13, 8, 0, 0, 0, time.UTC))
+	assert.Equal(t, 3, report.CurrentStreak, "the streak holds until the end of the next day")
+
+	report = BuildProgressReport(snapshots, time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC))
+	assert.Equal(t, 0, report.CurrentStreak)
+	assert.Equal(t, 3, report.LongestStreak)
+}
+
+func TestProgressReport_Write(t *testing.T) {
+	snapshots := []ProgressSnapshot{
+		snapshotAt(12, 9, map[string]int{"a.md": 1000}),
+		snapshotAt(12, 22, map[string]int{"a.md": 2500}),
+		snapshotAt(13, 22, map[string]int{"a.md": 2500, "b.md": 300}),
+	}
+	report := BuildProgressReport(snapshots, time.Date(2026, 10, 13, 23, 0, 0, 0, time.UTC))
+
+	var sb strings.Builder
+	require.NoError(t, report.Write(&sb, 1))
+	assert.Equal(t, `Day         Written  Total
+2026-10-13  +300     2,800
+
+Week      Written
+2026-W42  +1,800
+
+Current streak: 2 days
+Longest streak: 2 days
+
+Scenes changed since 2026-10-12 22:00:
+  b.md: 300 words (+300, new)
+`, sb.String())
+}
+
+func TestProgressReport_WriteEmpty(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, BuildProgressReport(nil, time.Now()).Write(&sb, 0))
+	assert.Equal(t, "No progress recorded yet.\n", sb.String())
+}
@@ -0,0 +1,147 @@
+package binder
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scene statuses.
+const (
+	SceneDraft   = "draft"
+	SceneRevised = "revised"
+	SceneFinal   = "final"
+)
+
+// Scene is a scene file split into its optional YAML front matter and the
+// prose that goes into the manuscript.
+type Scene struct {
+	Path      string   `yaml:"-"`
+	POV       string   `yaml:"pov,omitempty"`
+	Location  string   `yaml:"location,omitempty"`
+	StoryDate string   `yaml:"story_date,omitempty"`
+	Status    string   `yaml:"status,omitempty"` // draft, revised or final
+	Summary   string   `yaml:"summary,omitempty"`
+	Tags      []string `yaml:"tags,omitempty"`
+	// Body is the scene text with the front matter removed. It starts on
+	// line BodyLine of the file.
+	Body     string `yaml:"-"`
+	BodyLine int    `yaml:"-"`
+}
+
+// ReadScene reads a scene file and parses its front matter.
+func ReadScene(path string) (*Scene, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseScene(path, data)
+}
+
+// ParseScene parses the contents of a scene file. A scene may begin with a
+// YAML mapping delimited by "---" lines; the block is decoded into the
+// scene's fields and stripped from its Body. Files without one, including
+// those that open with a "---" thematic break, are returned verbatim.
+func ParseScene(path string, data []byte) (*Scene, error) {
+	scene := &Scene{Path: path, BodyLine: 1}
+	meta, body, ok := splitSceneFrontMatter(data)
+	if !ok {
+		scene.Body = string(data)
+		return scene, nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(meta, &node); err != nil {
+		if !frontMatterKeyPattern.Match(meta) {
+			scene.Body = string(data)
+			return scene, nil
+		}
+		return nil, fmt.Errorf("%s: invalid scene front matter: %w", path, err)
+	}
+	if len(node.Content) > 0 {
+		if node.Content[0].Kind != yaml.MappingNode {
+			// Prose between two thematic breaks rather than front matter.
+			scene.Body = string(data)
+			return scene, nil
+		}
+		if err := node.Decode(scene); err != nil {
+			return nil, fmt.Errorf("%s: invalid scene front matter: %w", path, err)
+		}
+	}
+	switch scene.Status {
+	case "", SceneDraft, SceneRevised, SceneFinal:
+	default:
+		return nil, fmt.Errorf("%s: unknown scene status %q (want draft, revised or final)", path, scene.Status)
+	}
+	scene.Body = string(body)
+	scene.BodyLine += bytes.Count(data[:len(data)-len(body)], []byte("\n"))
+	return scene, nil
+}
+
+// frontMatterKeyPattern matches a block whose first line starts like a YAML
+// mapping key, so that a block which fails to parse is reported as broken
+// front matter rather than taken for prose.
+var frontMatterKeyPattern = regexp.MustCompile(`^\s*[A-Za-z_][\w-]*:(\s|$)`)
+
+// splitSceneFrontMatter separates a leading "---" delimited YAML block from
+// the rest of a scene. The block may be closed by "---" or "...". ok is
+// false when the scene has no front matter.
+func splitSceneFrontMatter(data []byte) (meta, body []byte, ok bool) {
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+	first, rest, found := bytes.Cut(data, []byte("\n"))
+	if !found || strings.TrimRight(string(first), " \t\r") != "---" {
+		return nil, nil, false
+	}
+	offset := 0
+	for offset < len(rest) {
+		line, _, _ := bytes.Cut(rest[offset:], []byte("\n"))
+		end := offset + len(line) + 1
+		switch strings.TrimRight(string(line), " \t\r") {
+		case "---", "...":
+			body := []byte{}
+			if end < len(rest) {
+				body = bytes.TrimLeft(rest[end:], "\r\n")
+			}
+			return rest[:offset], body, true
+		}
+		offset = end
+	}
+	return nil, nil, false
+}
+
+// LoadScenes returns the chapter's parsed scenes, or the first error met
+// validating the chapter or reading its scene files.
+func (ic IteratedChapter) LoadScenes() ([]Scene, error) {
+	if err := ic.Validate(); err != nil {
+		return nil, err
+	}
+	if ic.sceneErr != nil {
+		return nil, ic.sceneErr
+	}
+	if ic.SceneData == nil && len(ic.Scenes) > 0 {
+		return loadScenes(ic.Scenes)
+	}
+	return ic.SceneData, nil
+}
+
+// loadScenes reads and parses scene files in order. Every path gets a
+// Scene, so that the result lines up with paths; the first error is
+// returned alongside.
+func loadScenes(paths []string) ([]Scene, error) {
+	scenes := make([]Scene, 0, len(paths))
+	var firstErr error
+	for _, path := range paths {
+		scene, err := ReadScene(path)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			scene = &Scene{Path: path, BodyLine: 1}
+		}
+		scenes = append(scenes, *scene)
+	}
+	return scenes, firstErr
+}
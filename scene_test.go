@@ -0,0 +1,123 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadScene_WithFrontMatter(t *testing.T) {
+	scene, err := ReadScene("testdata/scenes/annotated.md")
+	require.NoError(t, err)
+
+	assert.Equal(t, "testdata/scenes/annotated.md", scene.Path)
+	assert.Equal(t, "Mara", scene.POV)
+	assert.Equal(t, "The lighthouse", scene.Location)
+	assert.Equal(t, "1921-10-03", scene.StoryDate)
+	assert.Equal(t, SceneRevised, scene.Status)
+	assert.Equal(t, "Mara finds the logbook.", scene.Summary)
+	assert.Equal(t, []string{"logbook", "storm"}, scene.Tags)
+	assert.Equal(t, "The storm broke at midnight.\n", scene.Body)
+}
+
+func TestReadScene_WithoutFrontMatter(t *testing.T) {
+	scene, err := ReadScene("testdata/manuscript/foo.md")
+	require.NoError(t, err)
+
+	assert.Equal(t, "This is foo.", scene.Body)
+	assert.Empty(t, scene.POV)
+	assert.Empty(t, scene.Status)
+}
+
+func TestReadScene_FileNotFound(t *testing.T) {
+	_, err := ReadScene("testdata/scenes/nonexistent.md")
+	assert.Error(t, err)
+}
+
+func TestParseScene(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		body string
+		pov  string
+	}{
+		{"no front matter", "Just prose.\n", "Just prose.\n", ""},
+		{"dots close the block", "---\npov: Ann\n...\nProse.\n", "Prose.\n", "Ann"},
+		{"crlf line endings", "---\r\npov: Ann\r\n---\r\n\r\nProse.\r\n", "Prose.\r\n", "Ann"},
+		{"empty block", "---\n---\nProse.\n", "Prose.\n", ""},
+		{"front matter only", "---\npov: Ann\n---\n", "", "Ann"},
+		{"unclosed block is prose", "---\nProse after a rule.\n", "---\nProse after a rule.\n", ""},
+		{"rule later in the scene", "Prose.\n\n---\n\nMore.\n", "Prose.\n\n---\n\nMore.\n", ""},
+		{"opening rule", "---\nProse after a rule.\n\n---\n\nMore.\n", "---\nProse after a rule.\n\n---\n\nMore.\n", ""},
+		{"opening rule before unparsable prose", "---\nShe said [no.\n...\nMore.\n", "---\nShe said [no.\n...\nMore.\n", ""},
+		{"opening rule before a list", "---\n- one\n- two\n---\n", "---\n- one\n- two\n---\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scene, err := ParseScene("scene.md", []byte(tt.text))
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, scene.Body)
+			assert.Equal(t, tt.pov, scene.POV)
+		})
+	}
+}
+
+func TestParseScene_UnknownStatus(t *testing.T) {
+	_, err := ParseScene("scene.md", []byte("---\nstatus: done\n---\nProse.\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scene.md")
+	assert.Contains(t, err.Error(), `unknown scene status "done"`)
+}
+
+func TestParseScene_InvalidYAML(t *testing.T) {
+	_, err := ParseScene("scene.md", []byte("---\npov: [unclosed\n---\nProse.\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid scene front matter")
+}
+
+func TestIteratedChapter_LoadScenes(t *testing.T) {
+	chapter := IteratedChapter{
+		Scenes: []string{"testdata/scenes/annotated.md", "testdata/manuscript/bar.md"},
+	}
+	scenes, err := chapter.LoadScenes()
+	require.NoError(t, err)
+	require.Len(t, scenes, 2)
+	assert.Equal(t, "Mara", scenes[0].POV)
+	assert.Equal(t, "testdata/manuscript/bar.md", scenes[1].Path)
+	assert.Equal(t, "This is bar.", scenes[1].Body)
+}
+
+func TestGetChapters_SceneData(t *testing.T) {
+	book := &Book{BaseDir: "testdata/scenes", Chapters: []Chapter{{Scenes: []string{"annotated", "missing"}}}}
+	for chapter := range book.GetChapters() {
+		require.Len(t, chapter.SceneData, 2)
+		assert.Equal(t, "Mara", chapter.SceneData[0].POV)
+		assert.Equal(t, "The storm broke at midnight.\n", chapter.SceneData[0].Body)
+		assert.Equal(t, filepath.Join("testdata", "scenes", "missing.md"), chapter.SceneData[1].Path)
+		_, err := chapter.LoadScenes()
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	}
+}
+
+func TestWriteMarkdownScenes_StripsFrontMatter(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "output.md")
+	fd, err := os.Create(outFile)
+	require.NoError(t, err)
+
+	err = WriteMarkdownScenesOptions(fd, []string{"testdata/scenes/annotated.md", "testdata/manuscript/foo.md"}, SceneOptions{})
+	require.NoError(t, err)
+	fd.Close()
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	assert.Equal(t, "The storm broke at midnight.\n\n\n***\n\nThis is foo.", string(content))
+}
+
+func TestSceneWordCount_IgnoresFrontMatter(t *testing.T) {
+	count, err := SceneWordCount("testdata/scenes/annotated.md")
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+}
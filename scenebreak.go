@@ -0,0 +1,222 @@
+package binder
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SceneBreak sets how the break between two scenes is written, read from
+// the "scene_break" key of the book spec or of a chapter. In the spec it is
+// either a string, the text of the break in every format, or a mapping:
+//
+//	scene_break:
+//	  text: "⁂"          # centered text or glyph
+//	  image: fleuron.png # an image relative to base_dir
+//	  docx: "#"          # per-format overrides
+//	  latex: '\scenebreak'
+//
+// Each format takes its own field first, then the image, then the text. A
+// zero SceneBreak is each format's default: *** in markdown, a rule styled
+// with a fleuron in HTML and EPUB, the \scenebreak macro in LaTeX and a
+// centered # in DOCX. Thematic breaks such as *** inside a scene file are
+// explicit scene breaks and are written the same way.
+type SceneBreak struct {
+	Text     string `yaml:"text,omitempty"`
+	Image    string `yaml:"image,omitempty"`    // not used in DOCX
+	Markdown string `yaml:"markdown,omitempty"` // raw markdown
+	HTML     string `yaml:"html,omitempty"`     // raw XHTML, for HTML and EPUB
+	Latex    string `yaml:"latex,omitempty"`    // raw LaTeX
+	Docx     string `yaml:"docx,omitempty"`     // text of the centered Scene Break paragraph
+}
+
+// UnmarshalYAML accepts a plain string as shorthand for the text of the
+// break.
+func (s *SceneBreak) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = SceneBreak{}
+		return node.Decode(&s.Text)
+	}
+	type plain SceneBreak
+	return node.Decode((*plain)(s))
+}
+
+// IsZero reports whether the break is left to each format's default.
+func (s SceneBreak) IsZero() bool {
+	return s == SceneBreak{}
+}
+
+// resolve returns the break with its image path made relative to baseDir.
+func (s SceneBreak) resolve(baseDir string) SceneBreak {
+	if s.Image != "" && !filepath.IsAbs(s.Image) {
+		s.Image = filepath.Join(baseDir, s.Image)
+	}
+	return s
+}
+
+// htmlSceneBreak is the default scene break in HTML and EPUB, a rule that
+// each format's style sheet decorates.
+const htmlSceneBreak = "<hr class=\"scene-break\"/>\n"
+
+// markdown returns the break as a markdown paragraph.
+func (s SceneBreak) markdown() string {
+	switch {
+	case s.Markdown != "":
+		return s.Markdown
+	case s.Image != "":
+		return fmt.Sprintf("![%s](<%s>)", markdownEscape(s.Text), absPath(s.Image))
+	case s.Text != "":
+		return markdownEscape(s.Text)
+	default:
+		return "***"
+	}
+}
+
+// html returns the break as an XHTML fragment. Images are embedded as data
+// URLs so that the self-contained HTML page stays self-contained.
+func (s SceneBreak) html() (string, error) {
+	switch {
+	case s.HTML != "":
+		return s.HTML + "\n", nil
+	case s.Image != "":
+		kind := mime.TypeByExtension(strings.ToLower(filepath.Ext(s.Image)))
+		if !strings.HasPrefix(kind, "image/") {
+			return "", fmt.Errorf("scene break image %s is not a known image type", s.Image)
+		}
+		data, err := os.ReadFile(s.Image)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("<p class=\"scene-break\"><img src=\"data:%s;base64,%s\" alt=\"%s\"/></p>\n",
+			kind, base64.StdEncoding.EncodeToString(data), html.EscapeString(s.Text)), nil
+	case s.Text != "":
+		return fmt.Sprintf("<p class=\"scene-break\">%s</p>\n", html.EscapeString(s.Text)), nil
+	default:
+		return htmlSceneBreak, nil
+	}
+}
+
+// latex returns the break as a LaTeX paragraph. Text and images are set
+// with the \scenebreak macro's optional argument.
+func (s SceneBreak) latex() string {
+	switch {
+	case s.Latex != "":
+		return s.Latex
+	case s.Image != "":
+		return fmt.Sprintf("\\scenebreak[{\\includegraphics[height=1.5em]{%s}}]", filepath.ToSlash(absPath(s.Image)))
+	case s.Text != "":
+		return fmt.Sprintf("\\scenebreak[{%s}]", latexGlyphs.Replace(latexEscape(s.Text)))
+	default:
+		return "\\scenebreak"
+	}
+}
+
+// latexGlyphs maps the ornaments and typographic marks commonly used as
+// scene breaks to LaTeX commands, as the default pdfLaTeX fonts cannot set
+// them from UTF-8 input. The dingbats are from pifont's ZapfDingbats.
+var latexGlyphs = strings.NewReplacer(
+	"⁂", `\asterism{}`,
+	"❦", `\ding{166}`,
+	"❧", `\ding{167}`,
+	"✻", `\ding{91}`,
+	"✦", `\ding{70}`,
+	"✶", `\ding{86}`,
+	"★", `\ding{72}`,
+	"◆", `\ding{117}`,
+	"•", `\textbullet{}`,
+	"·", `\textperiodcentered{}`,
+	"§", `\S{}`,
+	"¶", `\P{}`,
+	"†", `\dag{}`,
+	"‡", `\ddag{}`,
+	"–", `\textendash{}`,
+	"—", `\textemdash{}`,
+	"…", `\dots{}`,
+)
+
+// docx returns the text of the break's Scene Break paragraph. Manuscript
+// format has no images, so DOCX falls back to the text.
+func (s SceneBreak) docx() string {
+	switch {
+	case s.Docx != "":
+		return s.Docx
+	case s.Text != "":
+		return s.Text
+	default:
+		return "#"
+	}
+}
+
+// replaceSceneBreaks replaces the thematic breaks in markdown text with
+// sceneBreak, keeping them blank-line separated from the text around them.
+// Lines in fenced code blocks are left alone.
+func replaceSceneBreaks(text, sceneBreak string) string {
+	lines := strings.Split(text, "\n")
+	var sb strings.Builder
+	fence := ""
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		trimmed := strings.TrimSpace(line)
+		if marker := codeFence(trimmed); fence == "" && marker != "" {
+			fence = marker
+		} else if fence != "" && strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			fence = ""
+			sb.WriteString(line)
+			continue
+		}
+		if fence != "" || !isThematicBreak(trimmed) {
+			sb.WriteString(line)
+			continue
+		}
+		if i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(sceneBreak)
+		if i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// codeFence returns the run of backticks or tildes that opens a fenced code
+// block on line, or "" if line does not open one.
+func codeFence(line string) string {
+	for _, c := range "`~" {
+		marker := line[:len(line)-len(strings.TrimLeft(line, string(c)))]
+		if len(marker) >= 3 {
+			return marker
+		}
+	}
+	return ""
+}
+
+// markdownEscape backslash-escapes the ASCII punctuation in text so that a
+// glyph such as # is not read as markup.
+func markdownEscape(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if r < 0x80 && strings.ContainsRune("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// absPath returns path made absolute, or path itself if that fails, for
+// output read from another directory.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,130 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSceneBreak_UnmarshalYAML(t *testing.T) {
+	var scalar SceneBreak
+	require.NoError(t, yaml.Unmarshal([]byte(`"#"`), &scalar))
+	assert.Equal(t, SceneBreak{Text: "#"}, scalar)
+
+	var mapping SceneBreak
+	require.NoError(t, yaml.Unmarshal([]byte("text: ❦\ndocx: \"#\"\nlatex: '\\fleuron'"), &mapping))
+	assert.Equal(t, SceneBreak{Text: "❦", Docx: "#", Latex: `\fleuron`}, mapping)
+}
+
+func TestSceneBreak_Formats(t *testing.T) {
+	glyph := SceneBreak{Text: "#"}
+	assert.Equal(t, `\#`, glyph.markdown())
+	assert.Equal(t, `\scenebreak[{\#}]`, glyph.latex())
+	assert.Equal(t, `\scenebreak[{\ding{167}}]`, SceneBreak{Text: "❧"}.latex())
+	assert.Equal(t, `\scenebreak[{\textbullet{} \textbullet{} \textbullet{}}]`, SceneBreak{Text: "• • •"}.latex())
+	assert.Equal(t, "#", glyph.docx())
+	html, err := glyph.html()
+	require.NoError(t, err)
+	assert.Equal(t, "<p class=\"scene-break\">#</p>\n", html)
+
+	none := SceneBreak{}
+	assert.Equal(t, "***", none.markdown())
+	assert.Equal(t, `\scenebreak`, none.latex())
+	assert.Equal(t, "#", none.docx())
+	html, err = none.html()
+	require.NoError(t, err)
+	assert.Equal(t, htmlSceneBreak, html)
+
+	custom := SceneBreak{Text: "⁂", Markdown: "::: break\n:::", HTML: `<div class="break"/>`, Latex: `\fleuron`, Docx: "#"}
+	assert.Equal(t, "::: break\n:::", custom.markdown())
+	assert.Equal(t, `\fleuron`, custom.latex())
+	assert.Equal(t, "#", custom.docx())
+	html, err = custom.html()
+	require.NoError(t, err)
+	assert.Equal(t, "<div class=\"break\"/>\n", html)
+}
+
+func TestSceneBreak_Image(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fleuron.png"), []byte("png"), 0644))
+	image := SceneBreak{Text: "* * *", Image: "fleuron.png"}.resolve(dir)
+
+	html, err := image.html()
+	require.NoError(t, err)
+	assert.Equal(t, "<p class=\"scene-break\"><img src=\"data:image/png;base64,cG5n\" alt=\"* * *\"/></p>\n", html)
+	assert.Equal(t, `![\* \* \*](<`+filepath.Join(dir, "fleuron.png")+`>)`, image.markdown())
+	assert.Contains(t, image.latex(), `\includegraphics[height=1.5em]{`+filepath.ToSlash(filepath.Join(dir, "fleuron.png"))+"}")
+	assert.Equal(t, "* * *", image.docx(), "DOCX falls back to the text")
+
+	_, err = SceneBreak{Image: "break.txt"}.resolve(dir).html()
+	assert.ErrorContains(t, err, "not a known image type")
+}
+
+func TestReplaceSceneBreaks(t *testing.T) {
+	assert.Equal(t, "One.\n\n⁂\n\nTwo.", replaceSceneBreaks("One.\n\n***\n\nTwo.", "⁂"))
+	assert.Equal(t, "One.\n\n⁂\n\nTwo.", replaceSceneBreaks("One.\n* * *\nTwo.", "⁂"))
+	assert.Equal(t, "No breaks -- here.", replaceSceneBreaks("No breaks -- here.", "⁂"))
+	code := "```\n***\n---\n```\n\n~~~~\n* * *\n~~~\n~~~~\n\n***"
+	assert.Equal(t, "```\n***\n---\n```\n\n~~~~\n* * *\n~~~\n~~~~\n\n⁂", replaceSceneBreaks(code, "⁂"))
+}
+
+func TestGetChapters_SceneBreakOverride(t *testing.T) {
+	_, book, err := LoadBook("testdata/book_with_scene_breaks.yaml")
+	require.NoError(t, err)
+
+	var breaks []SceneBreak
+	for chapter := range book.GetChapters() {
+		breaks = append(breaks, chapter.SceneBreak)
+	}
+	assert.Equal(t, []SceneBreak{{Text: "⁂", Docx: "#"}, {Text: "~"}}, breaks)
+}
+
+func TestAssembleMarkdown_ConfiguredSceneBreaks(t *testing.T) {
+	outdir := t.TempDir()
+	_, _, err := AssembleMarkdown(AssemblyConfig{
+		InputFile: "testdata/book_with_scene_breaks.yaml",
+		OutputDir: outdir,
+	})
+	require.NoError(t, err)
+
+	files, err := OutputFiles(outdir)
+	require.NoError(t, err)
+	var text strings.Builder
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		text.Write(content)
+	}
+	assert.Contains(t, text.String(), "This is foo.\n\n⁂\n\nFirst half.\n\n⁂\n\nSecond half.")
+	assert.Contains(t, text.String(), "This is bar.\n\n\\~\n\n")
+	assert.NotContains(t, text.String(), "***")
+}
+
+func TestAssembleFormats_ConfiguredSceneBreaks(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := AssembleHTML(HTMLConfig{InputFile: "testdata/book_with_scene_breaks.yaml", OutputFile: filepath.Join(dir, "book.html")})
+	require.NoError(t, err)
+	page, err := os.ReadFile(filepath.Join(dir, "book.html"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(page), `<p class="scene-break">⁂</p>`), "between scenes and within one")
+	assert.Contains(t, string(page), `<p class="scene-break">~</p>`)
+
+	_, err = AssembleLatex(LatexConfig{InputFile: "testdata/book_with_scene_breaks.yaml", OutputFile: filepath.Join(dir, "book.tex")})
+	require.NoError(t, err)
+	tex, err := os.ReadFile(filepath.Join(dir, "book.tex"))
+	require.NoError(t, err)
+	assert.Contains(t, string(tex), "This is foo.\n\n\\scenebreak[{\\asterism{}}]\n\nFirst half.\n\n\\scenebreak[{\\asterism{}}]\n\nSecond half.")
+	assert.Contains(t, string(tex), `\scenebreak[{\textasciitilde{}}]`)
+
+	_, err = AssembleDocx(DocxConfig{InputFile: "testdata/book_with_scene_breaks.yaml", OutputFile: filepath.Join(dir, "book.docx")})
+	require.NoError(t, err)
+	doc := readZipEntry(t, filepath.Join(dir, "book.docx"), "word/document.xml")
+	assert.NotContains(t, doc, "⁂")
+	assert.Contains(t, doc, "<w:t xml:space=\"preserve\">~</w:t>")
+}
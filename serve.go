@@ -0,0 +1,193 @@
+package binder
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// PreviewServer serves a live HTML preview of a book: a chapter index, a
+// page per chapter and a server-sent events endpoint that tells open pages
+// to reload whenever the book is rebuilt. Everything is rendered in memory.
+type PreviewServer struct {
+	InputFile string
+	Drafts    bool   // keep author notes in the preview
+	Critic    string // CriticMarkup mode; CriticAccept if empty
+
+	mu       sync.RWMutex
+	fm       *FrontMatter
+	sections []htmlSection
+	err      error // the last rebuild's error, shown in place of the book
+	clients  map[chan struct{}]struct{}
+	mux      *http.ServeMux
+}
+
+// NewPreviewServer returns a server for the book spec at inputFile. Call
+// Rebuild to render the book before serving.
+func NewPreviewServer(inputFile string) *PreviewServer {
+	s := &PreviewServer{
+		InputFile: inputFile,
+		clients:   map[chan struct{}]struct{}{},
+		mux:       http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /{$}", s.serveIndex)
+	s.mux.HandleFunc("GET /chapters/{id}", s.serveChapter)
+	s.mux.HandleFunc("GET /events", s.serveEvents)
+	return s
+}
+
+// Rebuild renders the book again and tells connected pages to reload. A
+// failed render is kept and displayed, so fixing the book clears it on the
+// next rebuild.
+func (s *PreviewServer) Rebuild() error {
+	err := ValidateCriticMode(s.Critic)
+	var fm *FrontMatter
+	var book *Book
+	if err == nil {
+		fm, book, err = LoadBook(s.InputFile)
+	}
+	var sections []htmlSection
+	if err == nil {
+		options := book.sceneOptions()
+		options.Drafts = s.Drafts
+		options.Critic = s.Critic
+		sections, err = renderHTMLSections(book, options, "/chapters/")
+	}
+	s.mu.Lock()
+	s.err = err
+	if err == nil {
+		s.fm, s.sections = fm, sections
+	}
+	for client := range s.clients {
+		select {
+		case client <- struct{}{}:
+		default:
+			// A reload is already pending for this client.
+		}
+	}
+	s.mu.Unlock()
+	return err
+}
+
+func (s *PreviewServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *PreviewServer) serveIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.fm == nil {
+		s.writePage(w, "Preview", "")
+		return
+	}
+	var toc tocBuilder
+	for _, section := range s.sections {
+		section.addToTOC(&toc, "/chapters/"+section.ID)
+	}
+	body := fmt.Sprintf("<header class=\"title-page\">\n%s</header>\n<nav class=\"toc\">\n<h2>%s</h2>\n<ol>\n%s</ol>\n</nav>\n",
+		htmlTitlePage(s.fm), html.EscapeString(frontMatterLocale(s.fm).contents()), toc.String())
+	s.writePage(w, s.fm.Title, body)
+}
+
+func (s *PreviewServer) serveChapter(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id := r.PathValue("id")
+	for i, section := range s.sections {
+		if section.ID != id {
+			continue
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "<section class=\"%s\" id=\"%s\">\n%s</section>\n", section.Class, section.ID, section.Body)
+		sb.WriteString("<nav class=\"pager\">")
+		if i > 0 {
+			fmt.Fprintf(&sb, "<a rel=\"prev\" href=\"/chapters/%s\">&larr; Previous</a>", s.sections[i-1].ID)
+		}
+		fmt.Fprintf(&sb, "<a href=\"/\">%s</a>", html.EscapeString(frontMatterLocale(s.fm).contents()))
+		if i+1 < len(s.sections) {
+			fmt.Fprintf(&sb, "<a rel=\"next\" href=\"/chapters/%s\">Next &rarr;</a>", s.sections[i+1].ID)
+		}
+		sb.WriteString("</nav>\n")
+		title := section.Heading
+		if title == "" {
+			title = s.fm.Title
+		}
+		s.writePage(w, title, sb.String())
+		return
+	}
+	// The chapter may have been renamed; the index has the new links.
+	http.NotFound(w, r)
+}
+
+// serveEvents streams a "reload" event to the browser after every rebuild.
+func (s *PreviewServer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	client := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-client:
+			fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writePage wraps body in the preview page template. Callers hold s.mu.
+func (s *PreviewServer) writePage(w http.ResponseWriter, title, body string) {
+	if s.err != nil {
+		body = fmt.Sprintf("<div class=\"error\"><h2>Build failed</h2><pre>%s</pre></div>\n", html.EscapeString(s.err.Error())) + body
+	}
+	lang := "en"
+	if s.fm != nil {
+		lang = EpubLanguage(s.fm)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, previewDocument, html.EscapeString(lang), html.EscapeString(title), htmlCSS+previewCSS, body)
+}
+
+const previewDocument = `<!DOCTYPE html>
+<html lang="%s">
+<head>
+<meta charset="utf-8"/>
+<meta name="viewport" content="width=device-width, initial-scale=1"/>
+<title>%s</title>
+<style>
+%s</style>
+</head>
+<body>
+<main>
+%s</main>
+<script>
+new EventSource("/events").addEventListener("reload", function () { location.reload(); });
+</script>
+</body>
+</html>
+`
+
+const previewCSS = `section { margin-top: 2em; }
+nav.pager { display: flex; justify-content: space-between; margin: 4em 0 2em; font-size: 0.9em; }
+nav.pager a { color: inherit; }
+.error { border: 1px solid #c33; background: #fee; padding: 0 1em; margin-bottom: 2em; }
+.error pre { white-space: pre-wrap; }
+`
@@ -0,0 +1,102 @@
+package binder
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getPage(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestPreviewServer_IndexAndChapters(t *testing.T) {
+	srv := NewPreviewServer("testdata/book_with_parts.yaml")
+	require.NoError(t, srv.Rebuild())
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	status, index := getPage(t, ts.URL+"/")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, index, `<h1 class="title">`)
+	assert.Contains(t, index, `<li><a href="/chapters/002-part-one-the-fall">Part One: The Fall</a>`)
+	assert.Contains(t, index, `new EventSource("/events")`)
+
+	status, chapter := getPage(t, ts.URL+"/chapters/003-chapter-one")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, chapter, "<title>Chapter One</title>")
+	assert.Contains(t, chapter, "<p>This is foo.</p>")
+	assert.Contains(t, chapter, `<a rel="prev" href="/chapters/002-part-one-the-fall">`)
+	assert.Contains(t, chapter, `<a rel="next" href="/chapters/004-interlude">`)
+
+	status, _ = getPage(t, ts.URL+"/chapters/999-missing")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestPreviewServer_ShowsBuildErrors(t *testing.T) {
+	root := copyManuscript(t)
+	srv := NewPreviewServer(filepath.Join(root, "book.yaml"))
+	require.NoError(t, srv.Rebuild())
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	require.NoError(t, os.Remove(filepath.Join(root, "manuscript", "bar.md")))
+	require.Error(t, srv.Rebuild())
+
+	// The last good build is still served beneath the error
+	_, index := getPage(t, ts.URL+"/")
+	assert.Contains(t, index, "Build failed")
+	assert.Contains(t, index, "bar.md")
+	assert.Contains(t, index, `href="/chapters/002-chapter-one"`)
+}
+
+func TestPreviewServer_ReloadEvents(t *testing.T) {
+	srv := NewPreviewServer("testdata/valid_book.yaml")
+	require.NoError(t, srv.Rebuild())
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			events <- scanner.Text()
+		}
+		close(events)
+	}()
+	require.Equal(t, ": connected", <-events)
+
+	require.NoError(t, srv.Rebuild())
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case line := <-events:
+			if strings.HasPrefix(line, "event: ") {
+				assert.Equal(t, "event: reload", line)
+				return
+			}
+		case <-timeout:
+			t.Fatal("no reload event received")
+		}
+	}
+}
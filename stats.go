@@ -0,0 +1,252 @@
+package binder
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formats accepted by BookStats.Write and BookAnalysis.Write.
+const (
+	StatsTable = "table"
+	StatsJSON  = "json"
+	StatsCSV   = "csv"
+)
+
+// ChapterStats holds the word counts of a chapter and its scenes.
+type ChapterStats struct {
+	Index int           `json:"index"` // 1-based position in reading order
+	Label string        `json:"heading"`
+	Part  *IteratedPart `json:"-"` // nil for chapters outside parts
+	// PartHeading names the chapter's part, empty outside parts and in
+	// unnamed ones.
+	PartHeading string            `json:"part,omitempty"`
+	Words       int               `json:"words"`
+	Target      int               `json:"target,omitempty"` // 0 when the chapter has no target
+	Scenes      []WordCountResult `json:"scenes"`
+}
+
+// PartStats totals the chapters of a part.
+type PartStats struct {
+	Heading string `json:"heading"`
+	Words   int    `json:"words"`
+	// Target is the part's target_words, or the sum of its chapters'
+	// targets when the part sets none.
+	Target int `json:"target,omitempty"`
+}
+
+// BookStats totals a book's chapters. Front and back matter are not
+// counted.
+type BookStats struct {
+	Title    string         `json:"title"`
+	Words    int            `json:"words"`
+	Target   int            `json:"target,omitempty"` // the book's target_words, or the sum of its parts and chapters
+	Chapters []ChapterStats `json:"chapters"`
+	Parts    []PartStats    `json:"parts,omitempty"`
+	// SceneRange is the book's scene_words; OutOfRange lists the scenes
+	// outside it.
+	SceneRange WordRange         `json:"-"`
+	OutOfRange []WordCountResult `json:"out_of_range,omitempty"`
+}
+
+// CollectStats counts the words of every scene in the book and rolls the
+// counts up into chapters, parts and the whole book. Words are counted in
+// countMode, or in the book's word_count mode when countMode is empty.
+func CollectStats(inputFile, countMode string) (*BookStats, error) {
+	frontMatter, book, err := LoadBook(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	if countMode != "" {
+		if err := ValidateCountMode(countMode); err != nil {
+			return nil, err
+		}
+		book.WordCount = countMode
+	}
+	stats := &BookStats{Title: frontMatter.Title, SceneRange: book.SceneWords}
+	childTargets := 0
+	var part *PartStats
+	index := 0
+	for chapter := range book.GetChapters() {
+		index += 1
+		if chapter.Matter != BodyMatter {
+			continue
+		}
+		if err := chapter.Validate(); err != nil {
+			return nil, err
+		}
+		if chapter.PartStart {
+			stats.Parts = append(stats.Parts, PartStats{Heading: chapter.Part.Heading, Target: chapter.Part.TargetWords})
+			part = &stats.Parts[len(stats.Parts)-1]
+			childTargets += part.Target
+		}
+		cs := ChapterStats{Index: index, Label: chapter.Label(), Part: chapter.Part, Target: chapter.TargetWords}
+		if chapter.Part != nil {
+			cs.PartHeading = chapter.Part.Heading
+		}
+		scenes, err := chapterWordCounts(book, index, chapter)
+		if err != nil {
+			return nil, err
+		}
+		cs.Scenes = scenes
+		for _, scene := range scenes {
+			cs.Words += scene.Count
+			if !book.SceneWords.Contains(scene.Count) {
+				stats.OutOfRange = append(stats.OutOfRange, scene)
+			}
+		}
+		stats.Words += cs.Words
+		if chapter.Part != nil {
+			part.Words += cs.Words
+			if chapter.Part.TargetWords == 0 {
+				part.Target += cs.Target
+				childTargets += cs.Target
+			}
+		} else {
+			childTargets += cs.Target
+		}
+		stats.Chapters = append(stats.Chapters, cs)
+	}
+	stats.Target = book.TargetWords
+	if stats.Target == 0 {
+		stats.Target = childTargets
+	}
+	return stats, nil
+}
+
+// PercentComplete returns words as a percentage of target, or "-" when
+// there is no target.
+func PercentComplete(words, target int) string {
+	if target <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", words*100/target)
+}
+
+// Write writes the stats in one of the StatsTable, StatsJSON or StatsCSV
+// formats.
+func (bs *BookStats) Write(w io.Writer, format string) error {
+	switch format {
+	case "", StatsTable:
+		return bs.WriteReport(w)
+	case StatsJSON:
+		return bs.WriteJSON(w)
+	case StatsCSV:
+		return bs.WriteCSV(w)
+	default:
+		return fmt.Errorf("unknown stats format %q (want table, json or csv)", format)
+	}
+}
+
+// WriteJSON writes the stats as an indented JSON document.
+func (bs *BookStats) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bs)
+}
+
+// WriteCSV writes one row per scene, followed by a subtotal row for each
+// chapter and part and a grand total, distinguished by the "kind" column.
+func (bs *BookStats) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	itoa := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	cw.Write([]string{"kind", "chapter_index", "chapter", "part", "subdir", "path", "scene", "words", "target"})
+	partIndex := -1
+	for i, cs := range bs.Chapters {
+		if cs.Part != nil && (i == 0 || bs.Chapters[i-1].Part != cs.Part) {
+			partIndex++
+		}
+		for _, scene := range cs.Scenes {
+			cw.Write([]string{"scene", strconv.Itoa(cs.Index), cs.Label, cs.PartHeading, scene.Subdir, scene.Path, scene.Scene, strconv.Itoa(scene.Count), ""})
+		}
+		cw.Write([]string{"chapter", strconv.Itoa(cs.Index), cs.Label, cs.PartHeading, "", "", "", strconv.Itoa(cs.Words), itoa(cs.Target)})
+		lastInPart := i+1 == len(bs.Chapters) || bs.Chapters[i+1].Part != cs.Part
+		if cs.Part != nil && lastInPart {
+			ps := bs.Parts[partIndex]
+			cw.Write([]string{"part", "", "", ps.Heading, "", "", "", strconv.Itoa(ps.Words), itoa(ps.Target)})
+		}
+	}
+	cw.Write([]string{"total", "", "", "", "", "", "", strconv.Itoa(bs.Words), itoa(bs.Target)})
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteReport writes a table of actual against target words per chapter,
+// with part and book totals, followed by the scenes outside the expected
+// scene length.
+func (bs *BookStats) WriteReport(w io.Writer) error {
+	var table strings.Builder
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	row := func(label string, scenes, words, target int) {
+		sceneCol := ""
+		if scenes >= 0 {
+			sceneCol = fmt.Sprint(scenes)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", label, sceneCol, FormatThousands(words), formatTarget(target), PercentComplete(words, target))
+	}
+	fmt.Fprintf(tw, "Chapter\tScenes\tWords\tTarget\tComplete\n")
+	partIndex := -1
+	for i, cs := range bs.Chapters {
+		if cs.Part != nil && (i == 0 || bs.Chapters[i-1].Part != cs.Part) {
+			partIndex++
+			if cs.Part.Heading != "" {
+				fmt.Fprintf(tw, "%s\t\t\t\t\n", cs.Part.Heading)
+			}
+		}
+		label := cs.Label
+		if cs.Part != nil && cs.Part.Heading != "" {
+			label = "  " + label
+		}
+		row(label, len(cs.Scenes), cs.Words, cs.Target)
+		lastInPart := i+1 == len(bs.Chapters) || bs.Chapters[i+1].Part != cs.Part
+		if cs.Part != nil && lastInPart {
+			ps := bs.Parts[partIndex]
+			heading := ps.Heading
+			if heading == "" {
+				heading = fmt.Sprintf("Part %d", cs.Part.Index)
+			}
+			row(heading+" total", -1, ps.Words, ps.Target)
+		}
+	}
+	row("Book total", -1, bs.Words, bs.Target)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	// Rows with empty trailing cells are padded; trim them.
+	for line := range strings.Lines(table.String()) {
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " \n")); err != nil {
+			return err
+		}
+	}
+	if len(bs.OutOfRange) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "\nScenes outside %s words:\n", bs.SceneRange)
+	for _, scene := range bs.OutOfRange {
+		direction := "over"
+		if scene.Count < bs.SceneRange.Min {
+			direction = "under"
+		}
+		if _, err := fmt.Fprintf(w, "  %s: %s words (%s)\n", filepath.Join(scene.Subdir, scene.Scene), FormatThousands(scene.Count), direction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatTarget(target int) string {
+	if target <= 0 {
+		return "-"
+	}
+	return FormatThousands(target)
+}
@@ -0,0 +1,151 @@
+package binder
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectStats(t *testing.T) {
+	stats, err := CollectStats("testdata/book_with_targets.yaml", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Book With Targets", stats.Title)
+	assert.Equal(t, 16, stats.Words)
+	assert.Equal(t, 40, stats.Target)
+
+	require.Len(t, stats.Chapters, 4)
+	assert.Equal(t, "Chapter One", stats.Chapters[0].Label)
+	assert.Equal(t, 6, stats.Chapters[0].Words)
+	assert.Equal(t, 10, stats.Chapters[0].Target)
+	assert.Len(t, stats.Chapters[0].Scenes, 2)
+	assert.Equal(t, "Interlude", stats.Chapters[3].Label)
+
+	require.Len(t, stats.Parts, 2)
+	assert.Equal(t, PartStats{Heading: "Part One", Words: 6, Target: 4}, stats.Parts[0])
+	assert.Equal(t, PartStats{Heading: "Part Two", Words: 4, Target: 20}, stats.Parts[1])
+
+	require.Len(t, stats.OutOfRange, 1)
+	assert.Equal(t, "testdata/manuscript/interlude1.md", stats.OutOfRange[0].Path)
+}
+
+func TestCollectStats_TargetRollup(t *testing.T) {
+	stats, err := CollectStats("testdata/book_with_parts.yaml", "")
+	require.NoError(t, err)
+	assert.Zero(t, stats.Target)
+	assert.Empty(t, stats.OutOfRange, "no scene range means no scene is out of range")
+}
+
+func TestCollectStats_SkipsMatterSections(t *testing.T) {
+	stats, err := CollectStats("testdata/book_with_matter.yaml", "")
+	require.NoError(t, err)
+	assert.Len(t, stats.Chapters, 2)
+	assert.Equal(t, 6, stats.Words)
+}
+
+func TestBookStats_WriteReport(t *testing.T) {
+	stats, err := CollectStats("testdata/book_with_targets.yaml", "")
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, stats.WriteReport(&sb))
+	report := sb.String()
+
+	assert.Equal(t, `Chapter          Scenes  Words  Target  Complete
+Chapter One      2       6      10      60%
+Part One
+  Chapter Two    1       3      4       75%
+  Chapter Three  1       3      -       -
+Part One total           6      4       150%
+Part Two
+  Interlude      1       4      -       -
+Part Two total           4      20      20%
+Book total               16     40      40%
+
+Scenes outside 3-3 words:
+  interlude1.md: 4 words (over)
+`, report)
+}
+
+func TestPercentComplete(t *testing.T) {
+	assert.Equal(t, "-", PercentComplete(100, 0))
+	assert.Equal(t, "50%", PercentComplete(500, 1000))
+	assert.Equal(t, "120%", PercentComplete(1200, 1000))
+}
+
+func TestWordRange(t *testing.T) {
+	r := WordRange{Min: 800, Max: 3000}
+	assert.True(t, r.Contains(800))
+	assert.True(t, r.Contains(3000))
+	assert.False(t, r.Contains(799))
+	assert.False(t, r.Contains(3001))
+	assert.True(t, WordRange{}.Contains(0))
+	assert.Equal(t, "800-3,000", r.String())
+	assert.Equal(t, "at least 800", WordRange{Min: 800}.String())
+	assert.Equal(t, "at most 3,000", WordRange{Max: 3000}.String())
+}
+
+func TestBookStats_WriteJSON(t *testing.T) {
+	stats, err := CollectStats("testdata/subdirs/book.yaml", "")
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, stats.Write(&sb, StatsJSON))
+
+	var decoded struct {
+		Title    string `json:"title"`
+		Words    int    `json:"words"`
+		Target   int    `json:"target"`
+		Chapters []struct {
+			Index   int    `json:"index"`
+			Heading string `json:"heading"`
+			Words   int    `json:"words"`
+			Target  int    `json:"target"`
+			Scenes  []WordCountResult
+		} `json:"chapters"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(sb.String()), &decoded))
+	assert.Equal(t, "Two Openings", decoded.Title)
+	assert.Equal(t, 7, decoded.Words)
+	assert.Equal(t, 8, decoded.Target)
+	require.Len(t, decoded.Chapters, 2)
+	assert.Equal(t, 2, decoded.Chapters[1].Index)
+	assert.Equal(t, "Chapter Two", decoded.Chapters[1].Heading)
+	assert.Equal(t, 8, decoded.Chapters[1].Target)
+	require.Len(t, decoded.Chapters[1].Scenes, 1)
+	assert.Equal(t, "two", decoded.Chapters[1].Scenes[0].Subdir)
+	assert.Equal(t, 4, decoded.Chapters[1].Scenes[0].Count)
+}
+
+func TestBookStats_WriteCSV(t *testing.T) {
+	stats, err := CollectStats("testdata/book_with_targets.yaml", "")
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, stats.Write(&sb, StatsCSV))
+	assert.Equal(t, `kind,chapter_index,chapter,part,subdir,path,scene,words,target
+scene,1,Chapter One,,,testdata/manuscript/foo.md,foo.md,3,
+scene,1,Chapter One,,,testdata/manuscript/baz.md,baz.md,3,
+chapter,1,Chapter One,,,,,6,10
+scene,2,Chapter Two,Part One,,testdata/manuscript/bar.md,bar.md,3,
+chapter,2,Chapter Two,Part One,,,,3,4
+scene,3,Chapter Three,Part One,,testdata/manuscript/quux.md,quux.md,3,
+chapter,3,Chapter Three,Part One,,,,3,
+part,,,Part One,,,,6,4
+scene,4,Interlude,Part Two,,testdata/manuscript/interlude1.md,interlude1.md,4,
+chapter,4,Interlude,Part Two,,,,4,
+part,,,Part Two,,,,4,20
+total,,,,,,,16,40
+`, sb.String())
+}
+
+func TestBookStats_WriteUnknownFormat(t *testing.T) {
+	stats, err := CollectStats("testdata/book_with_targets.yaml", "")
+	require.NoError(t, err)
+	err = stats.Write(io.Discard, "xml")
+	assert.ErrorContains(t, err, `unknown stats format "xml"`)
+}
@@ -0,0 +1,236 @@
+package binder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+// Typography locales, selecting quotation marks and spacing.
+const (
+	TypographyEnglish = "en" // “double” and ‘single’ quotes
+	TypographyFrench  = "fr" // « guillemets » with narrow no-break spaces
+	TypographyGerman  = "de" // „low-high“ and ‚single‘ quotes
+)
+
+// Typography controls the smart punctuation pass applied to scene text,
+// read from the "typography" key of the book spec.
+type Typography struct {
+	Smart bool `yaml:"smart,omitempty"`
+	// Locale picks the quotation style: en, fr or de. It defaults to the
+	// book's language, or English for languages without a style.
+	Locale string `yaml:"locale,omitempty"`
+}
+
+// Validate checks that the typography locale is known.
+func (t Typography) Validate() error {
+	switch t.Locale {
+	case "", TypographyEnglish, TypographyFrench, TypographyGerman:
+		return nil
+	default:
+		return fmt.Errorf("unknown typography locale %q (want en, fr or de)", t.Locale)
+	}
+}
+
+// resolve fills in the locale from the book's language.
+func (t Typography) resolve(bookLanguage string) Typography {
+	if t.Locale != "" {
+		return t
+	}
+	t.Locale = TypographyEnglish
+	if tag, err := language.Parse(bookLanguage); err == nil {
+		base, _ := tag.Base()
+		switch base.String() {
+		case TypographyFrench, TypographyGerman:
+			t.Locale = base.String()
+		}
+	}
+	return t
+}
+
+// Apply returns text with smart punctuation when the pass is enabled, and
+// text unchanged otherwise.
+func (t Typography) Apply(text string) string {
+	if !t.Smart {
+		return text
+	}
+	return SmartTypography(text, t.Locale)
+}
+
+// quoteMarks are a locale's opening and closing double and single quotes.
+type quoteMarks struct {
+	openDouble, closeDouble, openSingle, closeSingle rune
+}
+
+var typographyQuotes = map[string]quoteMarks{
+	TypographyEnglish: {'“', '”', '‘', '’'},
+	TypographyFrench:  {'«', '»', '‹', '›'},
+	TypographyGerman:  {'„', '“', '‚', '‘'},
+}
+
+const (
+	nbsp       = '\u00a0'
+	narrowNbsp = '\u202f'
+)
+
+// typographyProtected matches markdown that must pass through untouched:
+// HTML comments, inline code, autolinks and HTML tags, link destinations,
+// bare URLs and CriticMarkup delimiters.
+var typographyProtected = regexp.MustCompile("(?s)<!--.*?-->|`[^`]*`|<[^>\\s][^>]*>|\\]\\([^)]*\\)|\\b(?:https?|ftp)://\\S+|\\{(?:\\+\\+|--|~~|==|>>)|(?:\\+\\+|--|~~|==|<<)\\}|~>")
+
+// SmartTypography converts straight quotes to the locale's curly quotes or
+// guillemets, "--" and "---" to en and em dashes and "..." to an ellipsis.
+// HTML entities for non-breaking spaces become the characters themselves.
+// In French, guillemets and the high punctuation ; : ! ? get the no-break
+// spaces they are set with, replacing any ordinary space typed there.
+// Code, comments, HTML, URLs and scene break lines are left alone, as are
+// backslash-escaped characters.
+func SmartTypography(text, locale string) string {
+	marks, ok := typographyQuotes[locale]
+	if !ok {
+		marks = typographyQuotes[TypographyEnglish]
+	}
+	french := locale == TypographyFrench
+	text = strings.NewReplacer("&nbsp;", "\u00a0", "&#160;", "\u00a0", "&#8239;", "\u202f").Replace(text)
+
+	protected := typographyProtected.FindAllStringIndex(text, -1)
+	// Scene break lines such as "---" are protected too.
+	offset := 0
+	for line := range strings.Lines(text) {
+		if isThematicBreak(strings.TrimSpace(line)) {
+			protected = append(protected, []int{offset, offset + len(line)})
+		}
+		offset += len(line)
+	}
+	src := []rune(text)
+	// offsets holds the byte offset of each rune, to check against the
+	// protected spans.
+	offsets := make([]int, 0, len(src))
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	isProtected := func(i int) bool {
+		for _, span := range protected {
+			if offsets[i] >= span[0] && offsets[i] < span[1] {
+				return true
+			}
+		}
+		return false
+	}
+	out := make([]rune, 0, len(src))
+	// trimSpace drops the spaces just written, so that French spacing
+	// replaces rather than adds to them.
+	trimSpace := func() {
+		for len(out) > 0 && isInlineSpace(out[len(out)-1]) {
+			out = out[:len(out)-1]
+		}
+	}
+	// opensQuote reports whether the straight quote at i opens a quotation,
+	// judging by what comes before it. A quote with space on both sides, as
+	// typed in French, opens one unless one is already open.
+	opensQuote := func(i int, open bool) bool {
+		// Emphasis markers around a quote do not change which way it faces.
+		j := i - 1
+		for j >= 0 && (src[j] == '*' || src[j] == '_') {
+			j--
+		}
+		opening := j < 0 || unicode.IsSpace(src[j]) || strings.ContainsRune("([{<>—–-/“‘„‚«‹", src[j])
+		if opening && (i+1 == len(src) || unicode.IsSpace(src[i+1])) {
+			return !open
+		}
+		return opening
+	}
+	doubleOpen, singleOpen := false, false
+	// openGuillemet writes an opening quote for the one at i and returns
+	// the index of the last rune it consumed.
+	openGuillemet := func(i int, r rune) int {
+		out = append(out, r)
+		if !french {
+			return i
+		}
+		out = append(out, narrowNbsp)
+		for i+1 < len(src) && isInlineSpace(src[i+1]) && !isProtected(i+1) {
+			i++
+		}
+		return i
+	}
+	closeGuillemet := func(r rune) {
+		if french {
+			trimSpace()
+			out = append(out, narrowNbsp)
+		}
+		out = append(out, r)
+	}
+
+	for i := 0; i < len(src); i++ {
+		r := src[i]
+		if isProtected(i) {
+			out = append(out, r)
+			continue
+		}
+		next := func(k int) rune {
+			if i+k < len(src) {
+				return src[i+k]
+			}
+			return 0
+		}
+		switch {
+		case r == '\\' && i+1 < len(src):
+			out = append(out, r, src[i+1])
+			i++
+		case r == '-' && next(1) == '-' && next(2) == '-':
+			out = append(out, '—')
+			i += 2
+		case r == '-' && next(1) == '-':
+			out = append(out, '–')
+			i++
+		case r == '.' && next(1) == '.' && next(2) == '.':
+			out = append(out, '…')
+			i += 2
+		case r == '"' && opensQuote(i, doubleOpen):
+			i = openGuillemet(i, marks.openDouble)
+			doubleOpen = true
+		case r == '"':
+			closeGuillemet(marks.closeDouble)
+			doubleOpen = false
+		case r == '\'' && opensQuote(i, singleOpen) && unicode.IsDigit(next(1)):
+			out = append(out, '’') // an elided year, as in '90s
+		case r == '\'' && next(1) == 'n' && next(2) == '\'' && !unicode.IsLetter(next(3)):
+			out = append(out, '’', 'n', '’') // as in rock 'n' roll
+			i += 2
+		case r == '\'' && opensQuote(i, singleOpen):
+			i = openGuillemet(i, marks.openSingle)
+			singleOpen = true
+		case r == '\'' && i > 0 && unicode.IsLetter(src[i-1]) && unicode.IsLetter(next(1)):
+			out = append(out, '’') // an apostrophe, as in don't
+		case r == '\'' && singleOpen:
+			closeGuillemet(marks.closeSingle)
+			singleOpen = false
+		case r == '\'':
+			out = append(out, '’') // an apostrophe, as in the boys' toys
+		case french && (r == '«' || r == '‹'):
+			i = openGuillemet(i, r)
+		case french && (r == '»' || r == '›'):
+			closeGuillemet(r)
+		case french && strings.ContainsRune(";:!?", r) && len(out) > 0 && isInlineSpace(out[len(out)-1]):
+			trimSpace()
+			if r == ':' {
+				out = append(out, nbsp, r)
+			} else {
+				out = append(out, narrowNbsp, r)
+			}
+		default:
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
+
+// isInlineSpace reports whether r is a space within a line, including the
+// no-break spaces.
+func isInlineSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == nbsp || r == narrowNbsp
+}
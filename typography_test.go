@@ -0,0 +1,99 @@
+package binder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSmartTypography(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		in     string
+		want   string
+	}{
+		{"english quotes", TypographyEnglish, `"Hello," she said. 'Hi.'`, "“Hello,” she said. ‘Hi.’"},
+		{"apostrophes", TypographyEnglish, `It's the '90s, the dogs' bowls.`, "It’s the ’90s, the dogs’ bowls."},
+		{"nested quotes", TypographyEnglish, `"He said 'no.'"`, "“He said ‘no.’”"},
+		{"emphasized quote", TypographyEnglish, `*"Run!"*`, "*“Run!”*"},
+		{"dashes", TypographyEnglish, "pages 3--5, then---silence", "pages 3–5, then—silence"},
+		{"ellipsis", TypographyEnglish, "Well...", "Well…"},
+		{"nbsp entity", TypographyEnglish, "Mr.&nbsp;Smith", "Mr. Smith"},
+		{"escaped quote", TypographyEnglish, `a \"straight\" quote`, `a \"straight\" quote`},
+		{"scene break", TypographyEnglish, "One.\n\n---\n\nTwo.", "One.\n\n---\n\nTwo."},
+		{"comment", TypographyEnglish, `<!-- "a" -- b -->`, `<!-- "a" -- b -->`},
+		{"code", TypographyEnglish, "run `a --b \"c\"`", "run `a --b \"c\"`"},
+		{"link destination", TypographyEnglish, `["x"](http://e.com/a--b)`, "[“x”](http://e.com/a--b)"},
+		{"html tag", TypographyEnglish, `<span class="x">'a'</span>`, `<span class="x">‘a’</span>`},
+		{"french guillemets", TypographyFrench, `"Bonjour", dit-il.`, "« Bonjour », dit-il."},
+		{"french spaced quotes", TypographyFrench, `" Bonjour "`, "« Bonjour »"},
+		{"french typed guillemets", TypographyFrench, "« Oui »", "« Oui »"},
+		{"french high punctuation", TypographyFrench, "Quoi ? Non ! Ainsi : voilà ; fin", "Quoi ? Non ! Ainsi : voilà ; fin"},
+		{"french unspaced punctuation", TypographyFrench, "10:30 et l'homme", "10:30 et l’homme"},
+		{"german quotes", TypographyGerman, `"Ja," sagte er. 'So.'`, "„Ja,“ sagte er. ‚So.‘"},
+		{"german apostrophe", TypographyGerman, `Geht's?`, "Geht’s?"},
+		{"german possessive", TypographyGerman, `Hans' Auto, die Jungs' Sachen`, "Hans’ Auto, die Jungs’ Sachen"},
+		{"german possessive in english", TypographyGerman, `the boys' toys`, "the boys’ toys"},
+		{"french possessive", TypographyFrench, `the boys' toys`, "the boys’ toys"},
+		{"french rock 'n' roll", TypographyFrench, `rock 'n' roll`, "rock ’n’ roll"},
+		{"english rock 'n' roll", TypographyEnglish, `rock 'n' roll`, "rock ’n’ roll"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SmartTypography(tt.in, tt.locale))
+		})
+	}
+}
+
+func TestTypography_Apply(t *testing.T) {
+	assert.Equal(t, `"as typed"`, Typography{}.Apply(`"as typed"`))
+	assert.Equal(t, "“curly”", Typography{Smart: true}.Apply(`"curly"`))
+}
+
+func TestTypography_Resolve(t *testing.T) {
+	assert.Equal(t, TypographyFrench, Typography{}.resolve("fr-CA").Locale)
+	assert.Equal(t, TypographyGerman, Typography{}.resolve("de").Locale)
+	assert.Equal(t, TypographyEnglish, Typography{}.resolve("es").Locale)
+	assert.Equal(t, TypographyEnglish, Typography{}.resolve("").Locale)
+	assert.Equal(t, TypographyGerman, Typography{Locale: TypographyGerman}.resolve("fr").Locale)
+}
+
+func TestTypography_Validate(t *testing.T) {
+	assert.NoError(t, Typography{Locale: TypographyFrench}.Validate())
+	assert.ErrorContains(t, Typography{Locale: "it"}.Validate(), `unknown typography locale "it"`)
+}
+
+func TestWriteMarkdownScenes_Typography(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "test.md")
+	fd, err := os.OpenFile(outFile, os.O_CREATE|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	err = WriteMarkdownScenesOptions(fd, []string{"testdata/scenes/typography.md"}, SceneOptions{Typography: Typography{Smart: true, Locale: TypographyEnglish}, Drafts: true})
+	require.NoError(t, err)
+	fd.Close()
+
+	content, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	assert.Equal(t, "“Wait,” she said – and then… nothing. It’s the ’90s—don’t ask.\n\n***\n\n"+
+		"<!-- keep -- this -->\nSee `a--b` and [the “site”](https://example.com/a--b).\n", string(content))
+}
+
+func TestAssembleHTML_Typography(t *testing.T) {
+	root := copyManuscript(t)
+	spec := filepath.Join(root, "book.yaml")
+	data, err := os.ReadFile(spec)
+	require.NoError(t, err)
+	data = append(data, []byte("\n    typography:\n        smart: true\n")...)
+	require.NoError(t, os.WriteFile(spec, data, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "manuscript", "foo.md"), []byte(`"Quoted" text...`), 0644))
+
+	_, book, err := LoadBook(spec)
+	require.NoError(t, err)
+	assert.True(t, book.Typography.Smart)
+	html, err := renderBookHTML(&FrontMatter{Title: "T"}, book, book.sceneOptions())
+	require.NoError(t, err)
+	assert.Contains(t, html, "“Quoted” text…")
+}
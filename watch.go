@@ -0,0 +1,141 @@
+package binder
+
+import (
+	"context"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Default timings for a Watcher.
+const (
+	DefaultWatchInterval = 250 * time.Millisecond
+	DefaultWatchDebounce = 300 * time.Millisecond
+)
+
+// Watcher polls a book spec, the markdown files under its base_dir,
+// including chapter and part subdirectories, and every other file the book
+// references, such as front matter files and scene break images, for
+// changes. Polling keeps it portable and free of platform-specific
+// notification APIs.
+type Watcher struct {
+	InputFile string
+	Interval  time.Duration // how often to poll; DefaultWatchInterval if zero
+	// Debounce is how long the files must stay unchanged before a burst of
+	// saves is reported as one change; DefaultWatchDebounce if zero.
+	Debounce time.Duration
+	// Ignore lists files and directories to leave unwatched, such as the
+	// output directory when it lives inside base_dir.
+	Ignore []string
+}
+
+// fileState is what the watcher compares between polls.
+type fileState struct {
+	modTime int64 // nanoseconds since the epoch
+	size    int64
+}
+
+// Run polls until ctx is cancelled, calling onChange once after each
+// debounced burst of changes. The watched directories are re-read from the
+// spec on every poll, so changes to base_dir or subdirs take effect
+// immediately. Run returns nil when ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context, onChange func()) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	debounce := w.Debounce
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := w.snapshot()
+	var changedAt time.Time
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			current := w.snapshot()
+			if !maps.Equal(last, current) {
+				last = current
+				changedAt = now
+				pending = true
+				continue
+			}
+			if pending && now.Sub(changedAt) >= debounce {
+				pending = false
+				onChange()
+				// Rebuilding may take a while; start the next poll from
+				// what is on disk now rather than reporting the edits made
+				// during the rebuild twice.
+				if next := w.snapshot(); !maps.Equal(last, next) {
+					last = next
+					changedAt = time.Now()
+					pending = true
+				}
+			}
+		}
+	}
+}
+
+// snapshot records the state of the spec, every markdown file under the
+// book's base_dir and the files its chapters reference. A spec that fails
+// to load mid-edit is still watched so the fix is picked up.
+func (w *Watcher) snapshot() map[string]fileState {
+	files := map[string]fileState{}
+	if info, err := os.Stat(w.InputFile); err == nil {
+		files[w.InputFile] = fileState{info.ModTime().UnixNano(), info.Size()}
+	}
+	_, book, err := LoadBook(w.InputFile)
+	if err != nil {
+		return files
+	}
+	ignored := map[string]bool{}
+	for _, path := range w.Ignore {
+		if abs, err := filepath.Abs(path); err == nil {
+			ignored[abs] = true
+		}
+	}
+	_ = filepath.WalkDir(book.BaseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		abs, _ := filepath.Abs(path)
+		hidden := strings.HasPrefix(d.Name(), ".") && path != book.BaseDir
+		if ignored[abs] || hidden {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[path] = fileState{info.ModTime().UnixNano(), info.Size()}
+		}
+		return nil
+	})
+	for chapter := range book.chapters(false) {
+		referenced := chapter.Scenes
+		if chapter.SceneBreak.Image != "" {
+			referenced = append(referenced, chapter.SceneBreak.Image)
+		}
+		for _, path := range referenced {
+			if _, ok := files[path]; ok {
+				continue
+			}
+			if info, err := os.Stat(path); err == nil {
+				files[path] = fileState{info.ModTime().UnixNano(), info.Size()}
+			}
+		}
+	}
+	return files
+}
@@ -0,0 +1,106 @@
+package binder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runWatcher starts w in the background and returns a counter of onChange
+// calls along with a function that stops the watcher.
+func runWatcher(t *testing.T, w *Watcher) (*atomic.Int32, func()) {
+	t.Helper()
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx, func() { calls.Add(1) })
+	}()
+	// Let the watcher take its first snapshot
+	time.Sleep(3 * w.Interval)
+	return &calls, func() {
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+// touch rewrites a file with new content and a later modification time.
+func touch(t *testing.T, path, content string, offset time.Duration) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	at := time.Now().Add(offset)
+	require.NoError(t, os.Chtimes(path, at, at))
+}
+
+func TestWatcher_DebouncesBurstOfSaves(t *testing.T) {
+	root := copyManuscript(t)
+	w := &Watcher{InputFile: filepath.Join(root, "book.yaml"), Interval: 10 * time.Millisecond, Debounce: 80 * time.Millisecond}
+	calls, stop := runWatcher(t, w)
+	defer stop()
+
+	for i := range 5 {
+		touch(t, filepath.Join(root, "manuscript", "foo.md"), "Edited foo.", time.Duration(i+1)*time.Second)
+		time.Sleep(15 * time.Millisecond)
+	}
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load(), "a burst of saves should trigger a single rebuild")
+}
+
+func TestWatcher_WatchesSpecAndNewFiles(t *testing.T) {
+	root := copyManuscript(t)
+	spec := filepath.Join(root, "book.yaml")
+	w := &Watcher{InputFile: spec, Interval: 10 * time.Millisecond, Debounce: 20 * time.Millisecond}
+	calls, stop := runWatcher(t, w)
+	defer stop()
+
+	content, err := os.ReadFile(spec)
+	require.NoError(t, err)
+	touch(t, spec, string(content)+"\n", time.Second)
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Mkdir(filepath.Join(root, "manuscript", "part-two"), 0755))
+	touch(t, filepath.Join(root, "manuscript", "part-two", "new.md"), "A new scene.", 2*time.Second)
+	assert.Eventually(t, func() bool { return calls.Load() == 2 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWatcher_IgnoresOutputAndOtherFiles(t *testing.T) {
+	root := copyManuscript(t)
+	outdir := filepath.Join(root, "manuscript", "out")
+	require.NoError(t, os.Mkdir(outdir, 0755))
+	w := &Watcher{
+		InputFile: filepath.Join(root, "book.yaml"),
+		Interval:  10 * time.Millisecond,
+		Debounce:  20 * time.Millisecond,
+		Ignore:    []string{outdir},
+	}
+	calls, stop := runWatcher(t, w)
+	defer stop()
+
+	touch(t, filepath.Join(outdir, "001-chapter-one.md"), "generated", time.Second)
+	touch(t, filepath.Join(root, "manuscript", "notes.txt"), "not a scene", time.Second)
+	touch(t, filepath.Join(root, "manuscript", ".foo.md.swp"), "editor swap file", time.Second)
+	time.Sleep(150 * time.Millisecond)
+	assert.Zero(t, calls.Load())
+}
+
+func TestWatcher_WatchesReferencedFiles(t *testing.T) {
+	root := copyManuscript(t)
+	spec := filepath.Join(root, "book.yaml")
+	content, err := os.ReadFile(spec)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(spec, append(content, "\n    scene_break:\n        image: ../fleuron.png\n"...), 0644))
+	touch(t, filepath.Join(root, "fleuron.png"), "png", 0)
+	w := &Watcher{InputFile: spec, Interval: 10 * time.Millisecond, Debounce: 20 * time.Millisecond}
+	calls, stop := runWatcher(t, w)
+	defer stop()
+
+	touch(t, filepath.Join(root, "fleuron.png"), "new png", time.Second)
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,141 @@
+package binder
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Word counting modes, selected by the book's word_count setting.
+const (
+	// CountPlain counts whitespace-separated tokens, like wc -w.
+	CountPlain = "plain"
+	// CountMarkdown counts only the words of the prose: markup, author
+	// notes, scene breaks, links and URLs are left out, CriticMarkup changes
+	// are counted as accepted, dashes separate words and tokens of bare
+	// punctuation are not words.
+	CountMarkdown = "markdown"
+	// CountEstimate is the manuscript submission estimate of one word per
+	// six characters of prose, spaces included.
+	CountEstimate = "estimate"
+)
+
+// ValidateCountMode checks that mode is a known word counting mode. The
+// empty mode is CountPlain.
+func ValidateCountMode(mode string) error {
+	switch mode {
+	case "", CountPlain, CountMarkdown, CountEstimate:
+		return nil
+	default:
+		return fmt.Errorf("unknown word count mode %q (want plain, markdown or estimate)", mode)
+	}
+}
+
+// CountWords counts the words of scene text, without front matter, in the
+// given mode.
+func CountWords(text, mode string) (int, error) {
+	switch mode {
+	case "", CountPlain:
+		return len(strings.Fields(text)), nil
+	case CountMarkdown:
+		return len(proseWords(proseText(text))), nil
+	case CountEstimate:
+		chars := len([]rune(strings.Join(strings.Fields(proseText(text)), " ")))
+		return int(math.Round(float64(chars) / 6)), nil
+	default:
+		return 0, ValidateCountMode(mode)
+	}
+}
+
+// SceneWordCountMode counts the words in a scene file, excluding its front
+// matter, in the given mode.
+func SceneWordCountMode(path, mode string) (int, error) {
+	scene, err := ReadScene(path)
+	if err != nil {
+		return 0, err
+	}
+	return CountWords(scene.Body, mode)
+}
+
+var (
+	imagePattern    = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	autolinkPattern = regexp.MustCompile(`<[a-zA-Z][a-zA-Z0-9+.-]*:[^>\s]*>`)
+	urlPattern      = regexp.MustCompile(`\b(?:https?|ftp)://\S+|\bwww\.\S+`)
+	htmlTagPattern  = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+)
+
+// proseText strips a scene down to the text a reader would see: notes,
+// footnotes, images, URLs and HTML tags are removed, editorial changes are
+// accepted, links are reduced to their text and markdown block, list and
+// emphasis markers are dropped.
+func proseText(text string) string {
+	return strings.Join(proseBlocks(text), "\n") + "\n"
+}
+
+// proseBlocks is proseText split into its headings, paragraphs, quotes and
+// list items. Scene breaks are dropped.
+func proseBlocks(text string) []string {
+	text = ApplyCritic(StripNotes(text), CriticAccept)
+	text, _ = extractFootnotes(text)
+	text = replaceFootnoteRefs(text, func(string) string { return "" })
+	text = splitListItems(text)
+	text = imagePattern.ReplaceAllString(text, " ")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = autolinkPattern.ReplaceAllString(text, " ")
+	text = urlPattern.ReplaceAllString(text, " ")
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	var blocks []string
+	for _, block := range ParseMarkdown(text) {
+		if block.Kind == SceneBreakBlock {
+			continue
+		}
+		blocks = append(blocks, block.PlainText())
+	}
+	return blocks
+}
+
+// listItemPattern matches the marker of a bullet or numbered list item.
+var listItemPattern = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]+`)
+
+// splitListItems drops list item markers and sets each item apart as a
+// paragraph of its own.
+func splitListItems(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if isThematicBreak(strings.TrimSpace(line)) {
+			continue
+		}
+		if m := listItemPattern.FindStringIndex(line); m != nil {
+			lines[i] = "\n" + line[m[1]:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// proseWords splits prose into words the way CountMarkdown counts them.
+func proseWords(prose string) []string {
+	// A typewriter dash, "--", separates words like an em dash does.
+	tokens := strings.FieldsFunc(strings.ReplaceAll(prose, "--", " "), isWordSeparator)
+	words := tokens[:0]
+	for _, token := range tokens {
+		if strings.IndexFunc(token, isWordRune) >= 0 {
+			words = append(words, token)
+		}
+	}
+	return words
+}
+
+// isWordSeparator splits words on whitespace and on dashes, so that
+// "yes—no" is two words. Hyphens join words and are not separators.
+func isWordSeparator(r rune) bool {
+	return unicode.IsSpace(r) || unicode.Is(unicode.Pd, r) && r != '-' && r != '‐'
+}
+
+// isWordRune reports whether r can make a token a word; tokens of nothing
+// but punctuation or symbols, such as a stray "&", are not counted.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
@@ -0,0 +1,80 @@
+package binder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountWords_Markdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"plain prose", "The quick brown fox.", 4},
+		{"emphasis", "She *really* meant **it**.", 4},
+		{"heading", "## A Heading\n\nSome text.", 4},
+		{"scene break", "One.\n\n***\n\nTwo.", 2},
+		{"html comment", "Before <!-- a note\nover lines --> after.", 2},
+		{"em dash", "yes—no and this–that", 5},
+		{"double hyphen", "wait--what", 2},
+		{"hyphenated word", "a well-known fact", 3},
+		{"lone punctuation", "this & that … and — more", 4},
+		{"link", "See [the docs](https://example.com/docs) now.", 4},
+		{"image", "![a cover](cover.png) Look.", 1},
+		{"bare url", "Visit https://example.com/a-b today.", 2},
+		{"autolink", "Mail <mailto:me@example.com> me.", 2},
+		{"html tag", "Some <span class=\"x\">styled</span> text.", 3},
+		{"blockquote and list", "> quoted words\n\n- one item\n- two", 5},
+		{"unicode", "Ça va, señor? «Oui», dit-il.", 5},
+		{"numbered list", "Steps:\n\n1. mix\n2) bake", 3},
+		{"footnotes", "The river rose[^1].\n\n[^1]: County records,\n    1921.\n", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CountWords(tt.text, CountMarkdown)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCountWords_Plain(t *testing.T) {
+	got, err := CountWords("One.\n\n***\n\nyes—no <!-- x -->", CountPlain)
+	require.NoError(t, err)
+	assert.Equal(t, 6, got)
+}
+
+func TestCountWords_Estimate(t *testing.T) {
+	// "The quick brown fox jumps." is 26 characters: 26/6 rounds to 4.
+	got, err := CountWords("The *quick*   brown\nfox jumps.\n\n<!-- not counted -->", CountEstimate)
+	require.NoError(t, err)
+	assert.Equal(t, 4, got)
+}
+
+func TestCountWords_UnknownMode(t *testing.T) {
+	_, err := CountWords("words", "pages")
+	assert.ErrorContains(t, err, `unknown word count mode "pages"`)
+}
+
+func TestSceneWordCountMode_ExcludesFrontMatter(t *testing.T) {
+	plain, err := SceneWordCountMode("testdata/scenes/annotated.md", CountPlain)
+	require.NoError(t, err)
+	markdown, err := SceneWordCountMode("testdata/scenes/annotated.md", CountMarkdown)
+	require.NoError(t, err)
+	assert.Positive(t, markdown)
+	assert.LessOrEqual(t, markdown, plain)
+}
+
+func TestCollectStats_CountMode(t *testing.T) {
+	plain, err := CollectStats("testdata/book_with_targets.yaml", "")
+	require.NoError(t, err)
+	estimate, err := CollectStats("testdata/book_with_targets.yaml", CountEstimate)
+	require.NoError(t, err)
+	assert.NotEqual(t, plain.Words, estimate.Words)
+
+	_, err = CollectStats("testdata/book_with_targets.yaml", "pages")
+	assert.Error(t, err)
+}